@@ -56,8 +56,24 @@ func (data *marshalData) marshalString(val reflect.Value) error {
 	if err := data.WriteByte('"'); err != nil {
 		return err
 	}
-	if _, err := data.Write([]byte(val.String())); err != nil {
-		return err
+	for _, r := range val.String() {
+		switch {
+		case r == '"' || r == '\\':
+			data.WriteByte('\\')
+			data.WriteRune(r)
+		case r == '\n':
+			data.WriteString("\\n")
+		case r == '\r':
+			data.WriteString("\\r")
+		case r == '\t':
+			data.WriteString("\\t")
+		case r < 0x20:
+			data.WriteString(`\u00`)
+			data.WriteByte("0123456789abcdef"[r>>4])
+			data.WriteByte("0123456789abcdef"[r&0xf])
+		default:
+			data.WriteRune(r)
+		}
 	}
 	if err := data.WriteByte('"'); err != nil {
 		return err
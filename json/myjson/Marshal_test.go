@@ -41,6 +41,19 @@ func TestMarshalString(t *testing.T) {
 		t.Fatalf("[Error]Difference between output and expected,\noutput:%s\nexpected:%s\n", (string)(testB), "\"1\"")
 	}
 }
+func TestMarshalStringEscape(t *testing.T) {
+	for _, s := range []string{`a"b`, `C:\path`, "line1\nline2\t\r", "\x01中文"} {
+		var data marshalData
+		if err := data.marshalString(reflect.ValueOf(s)); err != nil {
+			t.Fatal(err)
+		}
+		var out string
+		if err := json.Unmarshal(data.Bytes(), &out); err != nil || out != s {
+			t.Fatalf("[Error]Difference between output and expected,\noutput:%s\nexpected:%q\n", data.Bytes(), s)
+		}
+	}
+}
+
 func TestMarshalSlice(t *testing.T) {
 	type test struct {
 		TestValue interface{}
@@ -0,0 +1,278 @@
+package ini
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yilin0041/service-computing/json/myjson"
+)
+
+//ToMap ：所有的值，section -> key -> value，默认section是 ""；不包括继承的key
+func (c *Config) ToMap() map[string]map[string]string {
+	return c.values()
+}
+
+//FromMap ：用m创建一个配置，默认section在最前面，section和key按字母顺序排列
+func FromMap(m map[string]map[string]string) *Config {
+	conf := &Config{data: make(map[string]map[string]string), doc: newDocument()}
+	sections := make([]string, 0, len(m))
+	for sec := range m {
+		sections = append(sections, sec)
+	}
+	sort.Strings(sections)
+	for _, sec := range sections {
+		keys := make([]string, 0, len(m[sec]))
+		for key := range m[sec] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		conf.data[sec] = make(map[string]string, len(keys))
+		conf.order.addSection(sec)
+		for _, key := range keys {
+			conf.put(sec, key, m[sec][key], Origin{Source: "FromMap"})
+			conf.doc.set(sec, key, m[sec][key], DuplicateLastWins)
+		}
+	}
+	return conf
+}
+
+//ToJSON ：把配置转换为JSON，默认section中的key在最外层，其它section是一个对象，
+//下级section（[a.b]）是上级section的对象中的对象；key和同一个对象中的section同名时返回错误
+func (c *Config) ToJSON() ([]byte, error) {
+	m := c.ToMap()
+	obj := make(map[string]interface{}, len(m[""]))
+	for key, value := range m[""] {
+		obj[key] = value
+	}
+	sections := make([]string, 0, len(m))
+	for sec := range m {
+		if sec != "" {
+			sections = append(sections, sec)
+		}
+	}
+	sort.Strings(sections)
+	for _, sec := range sections {
+		parent, path := obj, ""
+		for _, name := range strings.Split(sec, ".") {
+			path = keyPath(path, name)
+			child, ok := parent[name].(map[string]interface{})
+			if !ok {
+				if _, ok := parent[name]; ok {
+					return nil, fmt.Errorf("[Error]key %q conflicts with section %q", path, path)
+				}
+				child = make(map[string]interface{})
+				parent[name] = child
+			}
+			parent = child
+		}
+		for key, value := range m[sec] {
+			if _, ok := parent[key]; ok {
+				return nil, fmt.Errorf("[Error]key %q conflicts with section %q", keyPath(sec, key), keyPath(sec, key))
+			}
+			parent[key] = value
+		}
+	}
+	return myjson.Marshal(obj)
+}
+
+//FromJSON ：从JSON对象创建一个配置，最外层的值在默认section中，对象是section，
+//对象中的对象是下级section（[a.b]），只包含对象的对象不是section；数组的元素用 "," 连接，null是空字符串
+func FromJSON(data []byte) (*Config, error) {
+	var obj map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&obj); err != nil {
+		return nil, err
+	}
+	m := map[string]map[string]string{}
+	var add func(sec string, obj map[string]interface{}) error
+	add = func(sec string, obj map[string]interface{}) error {
+		if len(obj) == 0 {
+			m[sec] = make(map[string]string)
+		}
+		for key, v := range obj {
+			if child, ok := v.(map[string]interface{}); ok {
+				name := key
+				if sec != "" {
+					name = sec + "." + key
+				}
+				if err := add(name, child); err != nil {
+					return err
+				}
+				continue
+			}
+			s, err := jsonScalar(v)
+			if err != nil {
+				return fmt.Errorf("[Error]%s: %v", keyPath(sec, key), err)
+			}
+			if m[sec] == nil {
+				m[sec] = make(map[string]string)
+			}
+			m[sec][key] = s
+		}
+		return nil
+	}
+	if err := add("", obj); err != nil {
+		return nil, err
+	}
+	if len(m[""]) == 0 {
+		delete(m, "")
+	}
+	return FromMap(m), nil
+}
+
+func jsonScalar(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, x := range v {
+			s, err := jsonScalar(x)
+			if _, isArray := x.([]interface{}); err != nil || isArray {
+				return "", fmt.Errorf("arrays can only contain scalars")
+			}
+			items[i] = s
+		}
+		return strings.Join(items, ","), nil
+	}
+	return "", fmt.Errorf("unsupported value %v", v)
+}
+
+// dotenv中的名字：section中的 "." 和section与key之间都是 "__"；
+// 用 "__" 分开名字得不到原来的section和key时返回错误
+func dotenvName(sec, key string) (string, error) {
+	parts := []string{key}
+	if sec != "" {
+		parts = append(strings.Split(sec, "."), key)
+	}
+	for i, part := range parts {
+		parts[i] = envName(part)
+	}
+	name := strings.Join(parts, "__")
+	if !reflect.DeepEqual(strings.Split(name, "__"), parts) {
+		return "", fmt.Errorf("[Error]%s can't be written as an unambiguous dotenv name", keyPath(sec, key))
+	}
+	return name, nil
+}
+
+//ToDotenv ：把配置转换为dotenv格式，每行一个 SECTION__KEY=VALUE，[a.b] 中的key是 A__B__KEY，
+//名字转换为大写，不是字母或数字的字符转换为 "_"；值包含特殊字符时加上双引号。
+//名字不能读回原来的section和key时返回错误，例如key中有 "__" 或section以 "_" 结束
+func (c *Config) ToDotenv() ([]byte, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var buf bytes.Buffer
+	for _, sec := range c.order.sections {
+		for _, key := range c.order.keys[sec] {
+			name, err := dotenvName(sec, key)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(&buf, "%s=%s\n", name, dotenvValue(c.data[sec][key]))
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func dotenvValue(v string) string {
+	plain := true
+	for _, r := range v {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_-.,/:@+=%", r)) {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return v
+	}
+	r := strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "$", "\\$", "\n", "\\n", "\r", "\\r", "\t", "\\t")
+	return `"` + r.Replace(v) + `"`
+}
+
+//FromDotenv ：从dotenv格式创建一个配置，是ToDotenv的反向操作，名字转换为小写；
+//接受 "export " 前缀、"#" 注释、单引号（不转义）和双引号中的值
+func FromDotenv(data []byte) (*Config, error) {
+	m := map[string]map[string]string{}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	n := 0
+	for sc.Scan() {
+		n++
+		l := strings.TrimSpace(sc.Text())
+		if l == "" || l[0] == '#' {
+			continue
+		}
+		l = strings.TrimPrefix(l, "export ")
+		i := strings.IndexByte(l, '=')
+		if i <= 0 {
+			return nil, &ParseError{Line: n, Col: 1, Msg: "expected NAME=VALUE"}
+		}
+		parts := strings.Split(strings.ToLower(strings.TrimSpace(l[:i])), "__")
+		sec, key := strings.Join(parts[:len(parts)-1], "."), parts[len(parts)-1]
+		value, err := dotenvUnquote(strings.TrimSpace(l[i+1:]))
+		if err != nil {
+			return nil, &ParseError{Line: n, Col: i + 2, Msg: err.Error()}
+		}
+		if m[sec] == nil {
+			m[sec] = make(map[string]string)
+		}
+		m[sec][key] = value
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return FromMap(m), nil
+}
+
+func dotenvUnquote(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	switch s[0] {
+	case '\'':
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted value")
+		}
+		return s[1 : end+1], nil
+	case '"':
+		var b strings.Builder
+		for i := 1; i < len(s); i++ {
+			switch c := s[i]; {
+			case c == '"':
+				return b.String(), nil
+			case c == '\\' && i+1 < len(s):
+				i++
+				switch s[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 'r':
+					b.WriteByte('\r')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					b.WriteByte(s[i])
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", fmt.Errorf("unterminated quoted value")
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	return s, nil
+}
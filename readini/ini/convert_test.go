@@ -0,0 +1,113 @@
+package ini
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestToMap(t *testing.T) {
+	m := SetConfig("init.ini").ToMap()
+	if m[""]["app_mode"] != "development" || len(m) != 3 {
+		t.Errorf("[Error]TestToMap %v", m)
+	}
+	conf := FromMap(m)
+	if !reflect.DeepEqual(conf.ToMap(), m) {
+		t.Errorf("[Error]TestToMap FromMap %v", conf.ToMap())
+	}
+	if s := conf.Sections(); s[0] != "" || s[1] != "paths" || s[2] != "server" {
+		t.Errorf("[Error]TestToMap order %v", s)
+	}
+	var buf bytes.Buffer
+	conf.WriteTo(&buf)
+	conf2, _ := LoadBytes(buf.Bytes())
+	if !reflect.DeepEqual(conf2.ToMap(), m) {
+		t.Errorf("[Error]TestToMap WriteTo %s", buf.String())
+	}
+}
+
+func TestJSON(t *testing.T) {
+	conf, _ := LoadBytes([]byte("name = \"a \\\"quoted\\\" name\"\n[paths]\ndata = C:\\data\n[server.tls]\nport = 443\n"))
+	data, err := conf.ToJSON()
+	if err != nil {
+		t.Fatalf("[Error]TestJSON %v", err)
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err != nil || obj["name"] != `a "quoted" name` {
+		t.Errorf("[Error]TestJSON %s %v", data, err)
+	}
+	back, err := FromJSON(data)
+	if err != nil || !reflect.DeepEqual(back.ToMap(), conf.ToMap()) {
+		t.Errorf("[Error]TestJSON %v %v", back.ToMap(), err)
+	}
+
+	conf, err = FromJSON([]byte(`{"debug": true, "server": {"port": 8080, "hosts": ["a", "b"], "tls": {"cert": null}}}`))
+	expected := map[string]map[string]string{
+		"":           {"debug": "true"},
+		"server":     {"port": "8080", "hosts": "a,b"},
+		"server.tls": {"cert": ""},
+	}
+	if err != nil || !reflect.DeepEqual(conf.ToMap(), expected) {
+		t.Errorf("[Error]TestJSON FromJSON %v %v", conf.ToMap(), err)
+	}
+	data = []byte(`{"a":{"b":{"c":"1"}},"d":{}}`)
+	if conf, err = FromJSON(data); err != nil || len(conf.Sections()) != 2 {
+		t.Errorf("[Error]TestJSON FromJSON %v %v", conf.ToMap(), err)
+	}
+	if back, err := conf.ToJSON(); err != nil || string(back) != string(data) {
+		t.Errorf("[Error]TestJSON round trip %s %v", back, err)
+	}
+	if _, err := FromJSON([]byte(`{"a": [[1]]}`)); err == nil {
+		t.Errorf("[Error]TestJSON nested array")
+	}
+	conflict := FromMap(map[string]map[string]string{"": {"s": "1"}, "s": {"k": "v"}})
+	if _, err := conflict.ToJSON(); err == nil {
+		t.Errorf("[Error]TestJSON conflict")
+	}
+	conflict = FromMap(map[string]map[string]string{"s": {"k": "v"}, "s.k": {"a": "b"}})
+	if _, err := conflict.ToJSON(); err == nil {
+		t.Errorf("[Error]TestJSON conflict")
+	}
+}
+
+func TestDotenv(t *testing.T) {
+	conf, _ := LoadBytes([]byte("app_mode = dev\n[server.tls]\nhttp-port = 443\nmotd = \"hello $USER\\n\"\n"))
+	data, err := conf.ToDotenv()
+	expected := "APP_MODE=dev\nSERVER__TLS__HTTP_PORT=443\nSERVER__TLS__MOTD=\"hello \\$USER\\n\"\n"
+	if err != nil || string(data) != expected {
+		t.Errorf("[Error]TestDotenv %q %v", data, err)
+	}
+	back, err := FromDotenv(append(data, "# comment\nexport SINGLE='a \\n b'\nplain = x # comment\n"...))
+	if err != nil {
+		t.Fatalf("[Error]TestDotenv %v", err)
+	}
+	m := back.ToMap()
+	if m["server.tls"]["motd"] != "hello $USER\n" || m["server.tls"]["http_port"] != "443" ||
+		m[""]["single"] != `a \n b` || m[""]["plain"] != "x" || m[""]["app_mode"] != "dev" {
+		t.Errorf("[Error]TestDotenv %v", m)
+	}
+	if _, err := FromDotenv([]byte("A=1\nB\n")); err == nil || err.(*ParseError).Line != 2 {
+		t.Errorf("[Error]TestDotenv error %v", err)
+	}
+	if _, err := FromDotenv([]byte("A=\"open\n")); err == nil {
+		t.Errorf("[Error]TestDotenv unterminated")
+	}
+	for _, ambiguous := range []string{"[a]\nb__c = 2\n", "[a_]\nb = 2\n", "[a.b-]\nc = 2\n"} {
+		conf, _ := LoadBytes([]byte(ambiguous))
+		if _, err := conf.ToDotenv(); err == nil {
+			t.Errorf("[Error]TestDotenv ambiguous %q", ambiguous)
+		}
+	}
+	conf, _ = LoadBytes([]byte("_a = 1\n[a]\nb_c = 2\n"))
+	if data, err := conf.ToDotenv(); err != nil || string(data) != "_A=1\nA__B_C=2\n" {
+		t.Errorf("[Error]TestDotenv %q %v", data, err)
+	}
+}
+
+func BenchmarkToJSON(b *testing.B) {
+	conf := SetConfig("init.ini")
+	for i := 0; i < b.N; i++ {
+		conf.ToJSON()
+	}
+}
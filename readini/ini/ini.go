@@ -1,214 +1,179 @@
 package ini
 
 import (
-	"bufio"
 	"errors"
-	"fmt"
-	"io"
 	"os"
-	"runtime"
-	"strings"
+	"sync"
 	"time"
 )
 
-//Config : ini的结构体
+//Config : ini的结构体，读取一次后保存在内存中，可以被多个goroutine同时使用
 type Config struct {
 	filepath string
-	confList []map[string]map[string]string
-}
-
-var lasttime int64
-var system bool
+	opts     LoadOptions
+	sources  []interface{} // Load的来源，为空时只读取filepath
 
-func init() {
-	sysType := runtime.GOOS
-	if sysType == "linux" {
-		system = true
-	}
+	mu      sync.RWMutex
+	data    map[string]map[string]string   // section -> key -> value
+	doc     *document                      // 读到的文件，SetValue的修改也记录在这里，用于保存
+	origins map[string][]Origin            // 值的来源，用于Explain
+	order   order                          // section和key的声明顺序
+	multi   map[string]map[string][]string // DuplicateAccumulate时有多个值的key
 
-	if sysType == "windows" {
-		system = false
-	}
+	defaults [][3]string // Validate设置的默认值 {section, key, value}，每次读取后重新设置
 }
 
+//PollInterval : Watch检查文件是否修改的间隔
+var PollInterval = 100 * time.Millisecond
+
 const (
-	notFindValue  = "[Error]No This Value\n"
-	keyNotUnique  = "[Error]Key is repetitive\n"
-	fileError     = "[Error]The file can't open\n"
-	fileReadError = "[Error]The file can't read\n"
+	notFindValue = "[Error]No This Value\n"
+	keyNotUnique = "[Error]Key is repetitive\n"
 )
 
-//SetConfig ：初始化一个设置文件
+//SetConfig ：使用默认选项初始化一个设置文件，读取失败时返回一个空的配置，需要错误时使用Load；
+//和以前的版本一样不展开值中的引用，"$$" 和 "%%" 原样保留
 func SetConfig(filepath string) *Config {
-	conf := new(Config)
-	conf.filepath = filepath
-	conf.readList()
-	return conf
+	return LoadOptions{NoInterpolation: true}.SetConfig(filepath)
 }
 
-func (c *Config) unique(conf string) bool {
-	for _, v := range c.confList {
-		for k := range v {
-			if k == conf {
-				return false
-			}
-		}
+// 替换内存中的配置，返回一个保存原来内容的Config
+func (c *Config) swap(l *layers) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	old := &Config{filepath: c.filepath, opts: c.opts, sources: c.sources, data: c.data, doc: c.doc, origins: c.origins, order: c.order, multi: c.multi}
+	c.doc, c.data, c.origins, c.order, c.multi = l.doc, l.data, l.origins, l.order, l.multi
+	if c.sources != nil {
+		c.filepath = l.filepath
 	}
-	return true
+	return old
 }
 
-func (c *Config) readList() ([]map[string]map[string]string, error) {
-	file, err := os.Open(c.filepath)
+//Reload : 重新读取文件并整体替换内存中的配置，没有保存的SetValue会丢失；读取失败时配置不变
+func (c *Config) Reload() error {
+	l, err := c.merge()
 	if err != nil {
-		fmt.Printf("not open")
-		return nil, err
+		return err
+	}
+	c.swap(l)
+	return nil
+}
+
+//GetValue : 通过section和key来查找一个value，[a.b] 中没有的key从 [a] 中继承
+func (c *Config) GetValue(sec string, key string) (string, error) {
+	sec, key = c.opts.name(sec), c.opts.name(key)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if _, ok := c.data[sec]; !ok {
+		return "", errors.New(notFindValue)
+	}
+	value, _ := c.get(sec, key)
+	return value, nil
+}
+
+//GetValues : 一个key的所有值，LoadOptions.Duplicate是DuplicateAccumulate时按出现的顺序返回重复的key的值，
+//否则最多有一个值；key不存在时返回nil
+func (c *Config) GetValues(section, key string) []string {
+	section, key = c.opts.name(section), c.opts.name(key)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	sec, ok := c.find(section, key)
+	if !ok {
+		return nil
+	}
+	if values, ok := c.multi[sec][key]; ok {
+		return append([]string(nil), values...)
 	}
-	lasttime, err = getFileModTime(c.filepath)
-	defer file.Close()
-	var data map[string]map[string]string
-	var section string
-	first := true
-	buf := bufio.NewReader(file)
+	return []string{c.data[sec][key]}
+}
+
+// 查找key，依次查找section和它的上级section，c.mu必须被持有
+func (c *Config) get(sec, key string) (string, bool) {
+	sec, ok := c.find(sec, key)
+	return c.data[sec][key], ok
+}
+
+// key所在的section，是sec或它的上级section，c.mu必须被持有
+func (c *Config) find(sec, key string) (string, bool) {
 	for {
-		l, err := buf.ReadString('\n')
-		l = strings.TrimSpace(l)
-		if err != nil {
-			if err != io.EOF {
-				fmt.Printf("no this value")
-				return nil, err
-			}
-			if len(l) == 0 {
-				break
-			}
+		if _, ok := c.data[sec][key]; ok {
+			return sec, true
 		}
-		if (len(l) != 0) && (!system && string(l[0]) == "#" || system && string(l[0]) == ";") {
-			return nil, errors.New("System Error")
-		}
-		if (len(l) == 0) || (system && string(l[0]) == "#") || (!system && string(l[0]) == ";") {
-			continue
-		} else if l[0] == '[' && l[len(l)-1] == ']' {
-			section = strings.TrimSpace(l[1 : len(l)-1])
-			data = make(map[string]map[string]string)
-			data[section] = make(map[string]string)
-			first = false
-		} else if first {
-			section = ""
-			data = make(map[string]map[string]string)
-			data[section] = make(map[string]string)
-			first = false
-			i := strings.IndexAny(l, "=")
-			if i == -1 {
-				continue
-			}
-			value := strings.TrimSpace(l[i+1 : len(l)])
-			data[section][strings.TrimSpace(l[0:i])] = value
-			if c.unique(section) == true {
-				c.confList = append(c.confList, data)
-			}
-		} else {
-			i := strings.IndexAny(l, "=")
-			if i == -1 {
-				continue
-			}
-			value := strings.TrimSpace(l[i+1 : len(l)])
-			data[section][strings.TrimSpace(l[0:i])] = value
-			if c.unique(section) == true {
-				c.confList = append(c.confList, data)
-			}
+		parent, ok := parentOf(sec)
+		if !ok {
+			return "", false
 		}
+		sec = parent
 	}
-	return c.confList, nil
 }
 
-//GetValue : 通过section和key来查找一个value
-func (c *Config) GetValue(sec string, key string) (string, error) {
-	c.readList()
-	conf, err := c.readList()
-	if err != nil {
-		return "", err
+// 设置内存中的值并记录来源，c.mu必须被持有
+func (c *Config) put(sec, key, value string, o Origin) {
+	if c.data == nil {
+		c.data = make(map[string]map[string]string)
 	}
-	for _, v := range conf {
-		for k, val := range v {
-			if k == sec {
-				return val[key], nil
-			}
-		}
+	if c.data[sec] == nil {
+		c.data[sec] = make(map[string]string)
 	}
-	return "", errors.New(notFindValue)
+	c.data[sec][key] = value
+	if c.origins == nil {
+		c.origins = make(map[string][]Origin)
+	}
+	path := keyPath(sec, key)
+	o.Value = value
+	c.origins[path] = append([]Origin{o}, c.origins[path]...)
+	c.order.add(sec, key)
+	delete(c.multi[sec], key)
 }
 
-//SetValue :通过section和key来设置一个value
+//SetValue :通过section和key来设置一个value，有多个值的key只保留这个值
 func (c *Config) SetValue(section, key, value string) bool {
-	c.readList()
-	data := c.confList
-	var ok bool
-	var index = make(map[int]bool)
-	var conf = make(map[string]map[string]string)
-	for i, v := range data {
-		_, ok = v[section]
-		index[i] = ok
-	}
-	i, ok := func(m map[int]bool) (i int, v bool) {
-		for i, v := range m {
-			if v == true {
-				return i, true
-			}
-		}
-		return 0, false
-	}(index)
-	if ok {
-		c.confList[i][section][key] = value
-		return true
+	section, key = c.opts.name(section), c.opts.name(key)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.put(section, key, value, Origin{Source: "SetValue"})
+	if c.doc == nil {
+		c.doc = newDocument()
 	}
-	conf[section] = make(map[string]string)
-	conf[section][key] = value
-	c.confList = append(c.confList, conf)
+	c.doc.set(section, key, value, c.opts.Duplicate)
 	return true
 }
 
-//ListenFunc ：实现接口方法 listen 直接调用函数
+//ListenFunc ：实现Listener，配置变化时用文件名调用函数
 type ListenFunc func(string)
 
-//Listener  :一个特殊的接口，用来监听配置文件是否被修改，让开发者自己决定如何处理配置变化
-type Listener interface {
-	listen(inifile string)
+//OnChange : 实现Listener
+func (f ListenFunc) OnChange(old, new *Config, diff Diff) {
+	f(new.filepath)
 }
 
 func equal(conf1 *Config, conf2 *Config) bool {
-	for _, v := range conf1.confList {
-		for k, val := range v {
-			for key, value1 := range val {
-				value2, _ := conf2.GetValue(k, key)
-				if value1 != value2 {
-					return false
-				}
-			}
-		}
-	}
-	return true
+	var d Diff
+	d.Added, d.Removed, d.Modified = diff(conf1.values(), conf2.values())
+	return d.Empty()
 }
 
 func getFileModTime(path string) (int64, error) {
 	f, err := os.Open(path)
 	if err != nil {
-		return time.Now().Unix(), err
+		return time.Now().UnixNano(), err
 	}
 	defer f.Close()
 
 	fi, err := f.Stat()
 	if err != nil {
-		return time.Now().Unix(), err
+		return time.Now().UnixNano(), err
 	}
-	return fi.ModTime().Unix(), nil
+	return fi.ModTime().UnixNano(), nil
 }
 
+// 每隔PollInterval检查一次，直到文件被修改
 func (f ListenFunc) listen(infile string) {
+	lasttime, _ := getFileModTime(infile)
 	for {
+		time.Sleep(PollInterval)
 		newtime, err := getFileModTime(infile)
-		if err != nil {
-		}
-		if lasttime != newtime {
-			lasttime = newtime
+		if err == nil && lasttime != newtime {
 			break
 		}
 	}
@@ -1,13 +1,17 @@
 package ini
 
 import (
+	"os"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestSetConfig(t *testing.T) {
-	SetConfig("init.ini")
-	if !system {
-		t.Errorf("[Warning]System is Windows,Please rewrite TestSetConfig.You can normaly use this package")
+	conf := SetConfig("init.ini")
+	if len(conf.data) != 3 {
+		t.Errorf("[Error]TestSetConfig")
 	}
 }
 
@@ -17,38 +21,38 @@ func BenchmarkSetConfig(b *testing.B) {
 	}
 }
 
-func TestUnique(t *testing.T) {
+func TestHasSection(t *testing.T) {
 	conf := SetConfig("init.ini")
-	if !conf.unique("name") {
-		t.Errorf("[Error]TestUnique 1")
+	if conf.hasSection("name") {
+		t.Errorf("[Error]TestHasSection 1")
 	}
-	if conf.unique("paths") {
-		t.Errorf("[Error]TestUnique 2")
+	if !conf.hasSection("paths") {
+		t.Errorf("[Error]TestHasSection 2")
 	}
-	if conf.unique("server") {
-		t.Errorf("[Error]TestUnique 3")
+	if !conf.hasSection("server") {
+		t.Errorf("[Error]TestHasSection 3")
 	}
-	if conf.unique("") {
-		t.Errorf("[Error]TestUnique 4")
+	if !conf.hasSection("") {
+		t.Errorf("[Error]TestHasSection 4")
 	}
 }
 
-func BenchmarkUnique(b *testing.B) {
+func BenchmarkHasSection(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		conf := SetConfig("init.ini")
-		conf.unique("name")
+		conf.hasSection("name")
 	}
 }
 
 func TestReadList(t *testing.T) {
 	conf := SetConfig("init.ini")
-	conf.readList()
+	conf.Reload()
 }
 
 func BenchmarkReadList(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		conf := SetConfig("init.ini")
-		conf.readList()
+		conf.Reload()
 	}
 }
 
@@ -83,6 +87,63 @@ func BenchmarkSetValue(b *testing.B) {
 	}
 }
 
+func TestReload(t *testing.T) {
+	path := tempConfig(t, "a = 1\n")
+	conf := SetConfig(path)
+	conf.SetValue("", "b", "2")
+	os.WriteFile(path, []byte("a = 3\n"), 0644)
+	if v, _ := conf.GetValue("", "a"); v != "1" {
+		t.Errorf("[Error]TestReload should be cached")
+	}
+	if err := conf.Reload(); err != nil {
+		t.Errorf("[Error]TestReload %v", err)
+	}
+	a, _ := conf.GetValue("", "a")
+	b, _ := conf.GetValue("", "b")
+	if a != "3" || b != "" {
+		t.Errorf("[Error]TestReload %q %q", a, b)
+	}
+	os.WriteFile(path, []byte("[bad\n"), 0644)
+	if err := conf.Reload(); err == nil {
+		t.Errorf("[Error]TestReload should fail")
+	}
+	if a, _ := conf.GetValue("", "a"); a != "3" {
+		t.Errorf("[Error]TestReload failed reload changed the config")
+	}
+}
+
+func TestConcurrent(t *testing.T) {
+	conf := SetConfig("init.ini")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if v, _ := conf.GetValue("", "app_mode"); v != "development" {
+					t.Errorf("[Error]TestConcurrent %q", v)
+					return
+				}
+				conf.SetValue("server", "test", strconv.Itoa(i))
+				conf.GetInt("server", "test", 0)
+				if j%10 == 0 {
+					conf.Reload()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func BenchmarkGetValueParallel(b *testing.B) {
+	conf := SetConfig("init.ini")
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			conf.GetValue("paths", "data")
+		}
+	})
+}
+
 func TestEqual(t *testing.T) {
 	conf1 := SetConfig("init.ini")
 	conf2 := SetConfig("init.ini")
@@ -107,10 +168,22 @@ func BenchmarkGetFileModTime(b *testing.B) {
 		getFileModTime("init.ini")
 	}
 }
+// 复制init.ini，稍后修改它的修改时间
+func touchLater(t *testing.T) string {
+	data, _ := os.ReadFile("init.ini")
+	path := tempConfig(t, string(data))
+	go func() {
+		time.Sleep(3 * PollInterval)
+		later := time.Now().Add(time.Hour)
+		os.Chtimes(path, later, later)
+	}()
+	return path
+}
+
 func TestListen(t *testing.T) {
 	MyListen := func(string) {
 	}
-	ListenFunc.listen(MyListen, "init.ini")
+	ListenFunc.listen(MyListen, touchLater(t))
 }
 
 // func BenchmarkListen(b *testing.B) {
@@ -121,11 +194,14 @@ func TestListen(t *testing.T) {
 // 	}
 // }
 func TestWatch(t *testing.T) {
+	called := false
 	MyListen := func(string) {
+		called = true
 	}
-	conf1 := SetConfig("init.ini")
-	conf2, _ := Watch("init.ini", MyListen)
-	if !equal(conf1, conf2) {
+	path := touchLater(t)
+	conf1 := SetConfig(path)
+	conf2, _ := Watch(path, MyListen)
+	if !equal(conf1, conf2) || !called {
 		t.Errorf("Not Equal")
 	}
 }
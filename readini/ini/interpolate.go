@@ -0,0 +1,170 @@
+package ini
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// 值中的引用：
+//
+//	%(key)s          同一个section中的key，没有时是默认section中的key
+//	${key}           同上
+//	${section.key}   section中的key，section和key在最后一个 "." 处分开
+//	${ENV:VAR}       环境变量VAR
+//
+// "%%" 和 "$$" 表示 "%" 和 "$"。引用的值先被展开，循环引用和找不到的引用是错误。
+// 来自Env和FlagSet的值不是ini语法，原样使用，也可以被引用。
+
+// 展开所有的值，l.data被修改
+func (l *layers) interpolate(opts LoadOptions) error {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var resolve func(sec, key string, chain []string) error
+	// 在section sec中展开引用，chain是正在展开的key
+	lookup := func(sec string, chain []string) func(ref string) (string, error) {
+		return func(ref string) (string, error) {
+			if strings.HasPrefix(ref, "ENV:") {
+				v, ok := os.LookupEnv(ref[len("ENV:"):])
+				if !ok {
+					return "", fmt.Errorf("environment variable %s is not set", ref[len("ENV:"):])
+				}
+				return v, nil
+			}
+			rsec, rkey, ok := l.find(opts, sec, ref)
+			if !ok {
+				return "", fmt.Errorf("reference %s not found", ref)
+			}
+			if err := resolve(rsec, rkey, chain); err != nil {
+				return "", err
+			}
+			return l.data[rsec][rkey], nil
+		}
+	}
+	resolve = func(sec, key string, chain []string) error {
+		path := keyPath(sec, key)
+		switch state[path] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("reference cycle: %s", strings.Join(append(chain, path), " -> "))
+		}
+		if l.literal[path] {
+			state[path] = done
+			return nil
+		}
+		state[path] = visiting
+		value, err := expand(l.data[sec][key], lookup(sec, append(chain, path)))
+		if err != nil {
+			return err
+		}
+		l.data[sec][key] = value
+		state[path] = done
+		return nil
+	}
+
+	// 按顺序展开，错误总是同一个
+	var paths [][2]string
+	for sec, val := range l.data {
+		for key := range val {
+			paths = append(paths, [2]string{sec, key})
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return paths[i][0] < paths[j][0] || paths[i][0] == paths[j][0] && paths[i][1] < paths[j][1]
+	})
+	for _, p := range paths {
+		value := l.data[p[0]][p[1]]
+		err := resolve(p[0], p[1], nil)
+		// 有多个值的key，最后一个值就是l.data中的值
+		if values := l.multi[p[0]][p[1]]; err == nil && len(values) > 0 {
+			for i := 0; i < len(values)-1 && err == nil; i++ {
+				value = values[i]
+				values[i], err = expand(values[i], lookup(p[0], nil))
+			}
+			values[len(values)-1] = l.data[p[0]][p[1]]
+		}
+		if err != nil {
+			e := &ValueError{Section: p[0], Key: p[1], Value: value, Err: err}
+			if origins := l.origins[keyPath(p[0], p[1])]; len(origins) > 0 && origins[0].Line > 0 {
+				e.File, e.Line = origins[0].Source, origins[0].Line
+			}
+			return e
+		}
+	}
+	return nil
+}
+
+// 在section sec中引用ref时，ref对应的section和key；section中没有的key从上级section中查找
+func (l *layers) find(opts LoadOptions, sec, ref string) (string, string, bool) {
+	ref = strings.TrimSpace(ref)
+	if i := strings.LastIndexByte(ref, '.'); i >= 0 {
+		return l.findFrom(opts.name(ref[:i]), opts.name(ref[i+1:]))
+	}
+	key := opts.name(ref)
+	if rsec, rkey, ok := l.findFrom(sec, key); ok {
+		return rsec, rkey, ok
+	}
+	return l.findFrom("", key)
+}
+
+func (l *layers) findFrom(sec, key string) (string, string, bool) {
+	for {
+		if _, ok := l.data[sec][key]; ok {
+			return sec, key, true
+		}
+		parent, ok := parentOf(sec)
+		if !ok {
+			return "", "", false
+		}
+		sec = parent
+	}
+}
+
+// 把s中的引用替换为lookup的结果
+func expand(s string, lookup func(ref string) (string, error)) (string, error) {
+	if !strings.ContainsAny(s, "%$") {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c != '%' && c != '$') || i+1 == len(s) {
+			b.WriteByte(c)
+			continue
+		}
+		var ref string
+		switch next := s[i+1]; {
+		case next == c:
+			b.WriteByte(c)
+			i++
+			continue
+		case c == '%' && next == '(':
+			end := strings.Index(s[i:], ")s")
+			if end < 0 {
+				return "", errors.New("unterminated %(")
+			}
+			ref, i = s[i+2:i+end], i+end+1
+		case c == '$' && next == '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return "", errors.New("unterminated ${")
+			}
+			ref, i = s[i+2:i+end], i+end
+		default:
+			b.WriteByte(c)
+			continue
+		}
+		v, err := lookup(ref)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(v)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,167 @@
+package ini
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("INI_TEST_HOME", "/home/app")
+	path := tempConfig(t, `root = /srv
+home = ${ENV:INI_TEST_HOME}
+[paths]
+data = %(root)s/data
+logs = ${data}/logs
+cache = ${paths.data}/cache
+price = 100%% $$5
+plain = 50% $x
+[server]
+static = ${paths.cache}/static
+`)
+	conf, err := Load(path)
+	if err != nil {
+		t.Fatalf("[Error]TestInterpolate %v", err)
+	}
+	expected := map[string]map[string]string{
+		"":       {"home": "/home/app"},
+		"paths":  {"data": "/srv/data", "logs": "/srv/data/logs", "cache": "/srv/data/cache", "price": "100% $5", "plain": "50% $x"},
+		"server": {"static": "/srv/data/cache/static"},
+	}
+	for sec, val := range expected {
+		for key, value := range val {
+			if v, _ := conf.GetValue(sec, key); v != value {
+				t.Errorf("[Error]TestInterpolate %s.%s = %q", sec, key, v)
+			}
+		}
+	}
+	if s := conf.Explain("paths", "data"); !strings.Contains(s, `expanded from "%(root)s/data"`) {
+		t.Errorf("[Error]TestInterpolate Explain %s", s)
+	}
+
+	raw, _ := LoadOptions{NoInterpolation: true}.Load(path)
+	if v, _ := raw.GetValue("paths", "data"); v != "%(root)s/data" {
+		t.Errorf("[Error]TestInterpolate NoInterpolation %s", v)
+	}
+}
+
+func TestInterpolateRoundTrip(t *testing.T) {
+	fromJSON, _ := FromJSON([]byte(`{"a": {"v": "${HOME}", "p": "50% %(x)s"}}`))
+	fromEnv, _ := FromDotenv([]byte("A__V=\"\\${HOME}\"\nA__P='50% %(x)s'\n"))
+	for _, conf := range []*Config{fromJSON, fromEnv} {
+		var buf bytes.Buffer
+		conf.WriteTo(&buf)
+		loaded, err := LoadBytes(buf.Bytes())
+		if err != nil {
+			t.Fatalf("[Error]TestInterpolateRoundTrip %v\n%s", err, buf.String())
+		}
+		for key, value := range map[string]string{"v": "${HOME}", "p": "50% %(x)s"} {
+			if v, _ := loaded.GetValue("a", key); v != value {
+				t.Errorf("[Error]TestInterpolateRoundTrip %s = %q", key, v)
+			}
+		}
+	}
+
+	// 不展开引用时原样保存
+	path := tempConfig(t, "a = 1\n")
+	conf, _ := LoadOptions{NoInterpolation: true}.Load(path)
+	conf.SetValue("", "a", "${b}")
+	var buf bytes.Buffer
+	conf.WriteTo(&buf)
+	if buf.String() != "a = ${b}\n" {
+		t.Errorf("[Error]TestInterpolateRoundTrip NoInterpolation %q", buf.String())
+	}
+	if v, _ := SetConfig(tempConfig(t, "pw = a$$b\n")).GetValue("", "pw"); v != "a$$b" {
+		t.Errorf("[Error]TestInterpolateRoundTrip SetConfig %q", v)
+	}
+}
+
+func TestInterpolateError(t *testing.T) {
+	tests := []struct {
+		content string
+		key     string
+		line    int
+		msg     string
+	}{
+		{"a = ${b}\nb = ${c}\nc = ${a}\n", "a", 1, "reference cycle: a -> b -> c -> a"},
+		{"[s]\na = x\nb = ${t.a}\n", "b", 3, "reference t.a not found"},
+		{"a = ${ENV:INI_TEST_UNSET}\n", "a", 1, "environment variable INI_TEST_UNSET is not set"},
+		{"a = ${b\n", "a", 1, "unterminated ${"},
+	}
+	for _, test := range tests {
+		path := tempConfig(t, test.content)
+		_, err := Load(path)
+		var ve *ValueError
+		if !errors.As(err, &ve) || ve.Key != test.key || ve.Line != test.line || ve.File != path || ve.Err.Error() != test.msg {
+			t.Errorf("[Error]TestInterpolateError %q: %v", test.content, err)
+		}
+	}
+}
+
+func TestInclude(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "conf.d"), 0755)
+	files := map[string]string{
+		"main.ini":          "a = 1\n!include conf.d/db.ini\n[server]\ninclude = conf.d/server.ini\nport = 80\n",
+		"conf.d/db.ini":     "[db]\nhost = localhost\ninclude = user.ini\n",
+		"conf.d/user.ini":   "user = root\n",
+		"conf.d/server.ini": "host = example.com\nport = 8080\n",
+	}
+	for name, content := range files {
+		os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	}
+	conf, err := Load(filepath.Join(dir, "main.ini"))
+	if err != nil {
+		t.Fatalf("[Error]TestInclude %v", err)
+	}
+	expected := map[string]map[string]string{
+		"":       {"a": "1"},
+		"db":     {"host": "localhost", "user": "root"},
+		"server": {"host": "example.com", "port": "80"},
+	}
+	for sec, val := range expected {
+		for key, value := range val {
+			if v, _ := conf.GetValue(sec, key); v != value {
+				t.Errorf("[Error]TestInclude %s.%s = %q", sec, key, v)
+			}
+		}
+	}
+	if v, err := conf.GetValue("server", "include"); v != "" || err != nil {
+		t.Errorf("[Error]TestInclude include is not a key")
+	}
+
+	// 保存时不写入被引用的文件的内容
+	conf.SetValue("server", "port", "81")
+	conf.Save()
+	data, _ := os.ReadFile(filepath.Join(dir, "main.ini"))
+	if string(data) != strings.Replace(files["main.ini"], "80", "81", 1) {
+		t.Errorf("[Error]TestInclude Save %q", data)
+	}
+
+	os.WriteFile(filepath.Join(dir, "conf.d/user.ini"), []byte("!include ../main.ini\n"), 0644)
+	if _, err := Load(filepath.Join(dir, "main.ini")); err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Errorf("[Error]TestInclude cycle %v", err)
+	}
+	os.Remove(filepath.Join(dir, "conf.d/user.ini"))
+	if _, err := Load(filepath.Join(dir, "main.ini")); !errors.Is(err, os.ErrNotExist) || !strings.Contains(err.Error(), "db.ini:3: include user.ini") {
+		t.Errorf("[Error]TestInclude missing %v", err)
+	}
+}
+
+func BenchmarkInterpolate(b *testing.B) {
+	l := &layers{data: map[string]map[string]string{"": {"root": "/srv"}, "paths": {}}}
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		l.data["paths"][key] = "%(root)s/" + key + "/${paths.a}"
+	}
+	l.data["paths"]["a"] = "${root}/a"
+	for i := 0; i < b.N; i++ {
+		data := map[string]map[string]string{"": {"root": "/srv"}, "paths": {}}
+		for k, v := range l.data["paths"] {
+			data["paths"][k] = v
+		}
+		(&layers{data: data}).interpolate(LoadOptions{})
+	}
+}
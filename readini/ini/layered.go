@@ -0,0 +1,313 @@
+package ini
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+//Env : Load的一个来源，用环境变量 PREFIX_SECTION_KEY 覆盖前面的来源中已有的key，
+//默认section中的key对应 PREFIX_KEY；名字转换为大写，不是字母或数字的字符转换为 "_"
+type Env struct {
+	Prefix string
+}
+
+// key对应的环境变量名
+func (e Env) name(sec, key string) string {
+	name := keyPath(sec, key)
+	if e.Prefix != "" {
+		name = e.Prefix + "_" + name
+	}
+	return envName(name)
+}
+
+// 转换为大写，不是字母或数字的字符转换为 "_"
+func envName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+//Origin : 一个值的来源
+type Origin struct {
+	Source string // 文件名、"env NAME"、"flag --name" 或 "SetValue"
+	Line   int    // 值在文件中的行，不是文件时为0
+	Value  string
+}
+
+func (o Origin) String() string {
+	if o.Line > 0 {
+		return fmt.Sprintf("%s:%d", o.Source, o.Line)
+	}
+	return o.Source
+}
+
+// 合并所有来源的结果
+type layers struct {
+	filepath string
+	doc      *document
+	data     map[string]map[string]string
+	origins  map[string][]Origin // "section.key" -> 来源，最新的在前
+	order    order
+	multi    map[string]map[string][]string // DuplicateAccumulate时有多个值的key
+	literal  map[string]bool                // 来自环境变量和命令行参数的值，不展开
+}
+
+func (l *layers) set(sec, key, value string, o Origin) {
+	if l.data[sec] == nil {
+		l.data[sec] = make(map[string]string)
+	}
+	l.data[sec][key] = value
+	o.Value = value
+	path := keyPath(sec, key)
+	l.origins[path] = append([]Origin{o}, l.origins[path]...)
+	l.order.add(sec, key)
+	delete(l.multi[sec], key)
+	delete(l.literal, path)
+}
+
+// 设置一个不是ini语法的值，展开引用时原样使用
+func (l *layers) setLiteral(sec, key, value string, o Origin) {
+	l.set(sec, key, value, o)
+	if l.literal == nil {
+		l.literal = make(map[string]bool)
+	}
+	l.literal[keyPath(sec, key)] = true
+}
+
+// 给有多个值的key加一个值
+func (l *layers) accumulate(sec, key, value string, o Origin) {
+	values := []string{l.data[sec][key]}
+	if multi, ok := l.multi[sec][key]; ok {
+		values = multi
+	}
+	l.set(sec, key, value, o)
+	if l.multi == nil {
+		l.multi = make(map[string]map[string][]string)
+	}
+	if l.multi[sec] == nil {
+		l.multi[sec] = make(map[string][]string)
+	}
+	l.multi[sec][key] = append(values, value)
+}
+
+// 删除section中所有的key
+func (l *layers) clearSection(sec string) {
+	for key := range l.data[sec] {
+		delete(l.origins, keyPath(sec, key))
+		l.order.remove(sec, key)
+	}
+	l.data[sec] = make(map[string]string)
+	delete(l.multi, sec)
+}
+
+// 没有key的section也存在
+func (l *layers) addSection(sec string) {
+	if l.data[sec] == nil {
+		l.data[sec] = make(map[string]string)
+	}
+	l.order.addSection(sec)
+}
+
+//Load ：使用默认选项按顺序合并多个来源，见 LoadOptions.Load
+func Load(sources ...interface{}) (*Config, error) {
+	return LoadOptions{}.Load(sources...)
+}
+
+//Load ：按顺序合并多个来源，后面的值覆盖前面的值；来源可以是文件名（string）、FSFile、[]byte、io.Reader、
+//Env 或 *pflag.FlagSet。io.Reader在Load中被读完，Reload时使用读到的内容；
+//FlagSet中只有在命令行中设置过的flag生效，flag的名字是 "section.key" 或默认section中的 "key"；
+//Save、SetValue修改的是最后一个文件，最后一个文件不是文件名时只能用SaveTo保存；Reload重新合并所有来源。
+//文件不存在时返回的错误满足 errors.Is(err, ErrNotFound)，语法错误是 *ParseError
+func (opts LoadOptions) Load(sources ...interface{}) (*Config, error) {
+	sources = append([]interface{}(nil), sources...)
+	for i, src := range sources {
+		if r, ok := src.(io.Reader); ok {
+			data, err := io.ReadAll(r)
+			if err != nil {
+				return nil, err
+			}
+			sources[i] = data
+		}
+	}
+	conf := &Config{opts: opts, sources: sources}
+	if err := conf.Reload(); err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+// 读取并合并所有的来源，不改变c
+func (c *Config) merge() (*layers, error) {
+	sources := c.sources
+	if sources == nil {
+		sources = []interface{}{c.filepath}
+	}
+	l := &layers{doc: newDocument(), data: make(map[string]map[string]string), origins: make(map[string][]Origin)}
+	for _, src := range sources {
+		switch src := src.(type) {
+		case string:
+			doc, err := l.addFile(c.opts, nil, src, "", nil)
+			if err != nil {
+				return nil, err
+			}
+			l.filepath, l.doc = src, doc
+		case FSFile:
+			doc, err := l.addFile(c.opts, src.FS, src.Path, "", nil)
+			if err != nil {
+				return nil, err
+			}
+			l.filepath, l.doc = "", doc
+		case []byte:
+			doc, err := parse(bytes.NewReader(src), c.opts)
+			if err != nil {
+				return nil, err
+			}
+			if err := l.addDoc(c.opts, nil, "", doc, "", nil); err != nil {
+				return nil, err
+			}
+			l.filepath, l.doc = "", doc
+		case Env:
+			for sec, val := range l.data {
+				for key := range val {
+					name := src.name(sec, key)
+					if value, ok := os.LookupEnv(name); ok {
+						l.setLiteral(sec, key, value, Origin{Source: "env " + name})
+					}
+				}
+			}
+		case *pflag.FlagSet:
+			src.Visit(func(f *pflag.Flag) {
+				sec, key := "", f.Name
+				if i := strings.LastIndexByte(key, '.'); i >= 0 {
+					sec, key = key[:i], key[i+1:]
+				}
+				l.setLiteral(c.opts.name(sec), c.opts.name(key), f.Value.String(), Origin{Source: "flag --" + f.Name})
+			})
+		default:
+			return nil, fmt.Errorf("[Error]unsupported source %T", src)
+		}
+	}
+	if !c.opts.NoInterpolation {
+		if err := l.interpolate(c.opts); err != nil {
+			return nil, err
+		}
+	}
+	c.mu.RLock()
+	defaults := c.defaults
+	c.mu.RUnlock()
+	for _, d := range defaults {
+		if _, _, ok := l.findFrom(d[0], d[1]); !ok {
+			l.set(d[0], d[1], d[2], Origin{Source: "default"})
+		}
+	}
+	return l, nil
+}
+
+// 读取一个文件和它引用的文件，fsys为nil时从操作系统的文件系统读取；
+// 默认section中的key加入section into；parents是正在读取的文件，用于发现循环引用
+func (l *layers) addFile(opts LoadOptions, fsys fs.FS, name, into string, parents []string) (*document, error) {
+	id := path.Clean(name)
+	if fsys == nil {
+		var err error
+		if id, err = filepath.Abs(name); err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range parents {
+		if p == id {
+			return nil, fmt.Errorf("[Error]include cycle: %s", strings.Join(append(parents, id), " -> "))
+		}
+	}
+	data, err := readFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parse(bytes.NewReader(data), opts)
+	if err != nil {
+		if e, ok := err.(*ParseError); ok {
+			e.File = name
+		}
+		return nil, err
+	}
+	return doc, l.addDoc(opts, fsys, name, doc, into, append(parents, id))
+}
+
+// 加入一个解析过的文件的值，name为空时文件来自Load的[]byte或io.Reader，引用的文件相对于当前目录
+func (l *layers) addDoc(opts LoadOptions, fsys fs.FS, name string, doc *document, into string, parents []string) error {
+	source, dir := name, "."
+	if name == "" {
+		source = "<bytes>"
+	} else if fsys == nil {
+		dir = filepath.Dir(name)
+	} else {
+		dir = path.Dir(name)
+	}
+	for _, sec := range doc.sections {
+		secName := sec.name
+		if secName == "" {
+			secName = into
+		} else {
+			l.addSection(secName)
+		}
+		if sec.replace {
+			l.clearSection(secName)
+		}
+		for _, it := range sec.items {
+			switch {
+			case it.include != "":
+				inc := it.include
+				if fsys != nil {
+					inc = path.Join(dir, inc)
+				} else if !filepath.IsAbs(inc) {
+					inc = filepath.Join(dir, inc)
+				}
+				if _, err := l.addFile(opts, fsys, inc, secName, parents); err != nil {
+					return fmt.Errorf("[Error]%s:%d: include %s: %w", source, it.line, it.include, err)
+				}
+			case it.comment:
+			case it.dup && opts.Duplicate == DuplicateFirstWins:
+			case it.dup && opts.Duplicate == DuplicateAccumulate:
+				l.accumulate(secName, it.key, it.value, Origin{Source: source, Line: it.line})
+			default:
+				l.set(secName, it.key, it.value, Origin{Source: source, Line: it.line})
+			}
+		}
+	}
+	return nil
+}
+
+//Explain ：说明一个值从哪里来，依次列出覆盖了前面的值的来源，用于调试
+func (c *Config) Explain(section, key string) string {
+	section, key = c.opts.name(section), c.opts.name(key)
+	path := keyPath(section, key)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	origins := c.origins[path]
+	if len(origins) == 0 {
+		return path + " is not set"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s = %q (%s", path, c.data[section][key], origins[0])
+	if origins[0].Value != c.data[section][key] {
+		fmt.Fprintf(&b, ", expanded from %q", origins[0].Value)
+	}
+	b.WriteString(")")
+	for _, o := range origins[1:] {
+		fmt.Fprintf(&b, "\n\toverrides %q (%s)", o.Value, o)
+	}
+	return b.String()
+}
@@ -0,0 +1,111 @@
+package ini
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestEnvName(t *testing.T) {
+	if n := (Env{Prefix: "APP"}).name("server", "http-port"); n != "APP_SERVER_HTTP_PORT" {
+		t.Errorf("[Error]TestEnvName %s", n)
+	}
+	if n := (Env{}).name("", "app_mode"); n != "APP_MODE" {
+		t.Errorf("[Error]TestEnvName %s", n)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	base := tempConfig(t, "mode = dev\n[server]\nport = 8080\nhost = localhost\ndebug = true\n")
+	prod := tempConfig(t, "mode = prod\n[server]\nport = 80\n")
+	t.Setenv("APP_SERVER_HOST", "example.com")
+	t.Setenv("APP_SERVER_PORT", "8081")
+	t.Setenv("APP_SERVER_UNKNOWN", "x")
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("server.port", "1", "")
+	fs.String("server.debug", "false", "")
+	fs.String("log", "", "")
+	fs.Parse([]string{"--server.port=9090", "--log=stderr"})
+
+	conf, err := Load(base, prod, Env{Prefix: "APP"}, fs)
+	if err != nil {
+		t.Fatalf("[Error]TestLoad %v", err)
+	}
+	expected := map[string]map[string]string{
+		"":       {"mode": "prod", "log": "stderr"},
+		"server": {"port": "9090", "host": "example.com", "debug": "true"},
+	}
+	for sec, val := range expected {
+		for key, value := range val {
+			if v, _ := conf.GetValue(sec, key); v != value {
+				t.Errorf("[Error]TestLoad %s.%s = %q", sec, key, v)
+			}
+		}
+	}
+	if v, _ := conf.GetValue("server", "unknown"); v != "" {
+		t.Errorf("[Error]TestLoad unknown env key")
+	}
+	if conf.filepath != prod {
+		t.Errorf("[Error]TestLoad filepath %s", conf.filepath)
+	}
+
+	t.Setenv("APP_SERVER_HOST", "example.org")
+	conf.Reload()
+	if v, _ := conf.GetValue("server", "host"); v != "example.org" {
+		t.Errorf("[Error]TestLoad Reload %s", v)
+	}
+
+	if _, err := Load(base, 42); err == nil {
+		t.Errorf("[Error]TestLoad unsupported source")
+	}
+	if _, err := Load(base, base+".missing"); err == nil {
+		t.Errorf("[Error]TestLoad missing file")
+	}
+}
+
+func TestLoadLiteral(t *testing.T) {
+	base := tempConfig(t, "[db]\nuser = admin\npassword = x\nurl = ${user}:${password}@db\n")
+	t.Setenv("APP_DB_PASSWORD", "a$$b${X}")
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("db.user", "", "")
+	fs.Parse([]string{"--db.user=%(u)s%"})
+
+	conf, err := Load(base, Env{Prefix: "APP"}, fs)
+	if err != nil {
+		t.Fatalf("[Error]TestLoadLiteral %v", err)
+	}
+	expected := map[string]string{"user": "%(u)s%", "password": "a$$b${X}", "url": "%(u)s%:a$$b${X}@db"}
+	for key, value := range expected {
+		if v, _ := conf.GetValue("db", key); v != value {
+			t.Errorf("[Error]TestLoadLiteral %s = %q", key, v)
+		}
+	}
+}
+
+func TestExplain(t *testing.T) {
+	base := tempConfig(t, "[server]\nport = 8080\n")
+	t.Setenv("APP_SERVER_PORT", "8081")
+	conf, _ := Load(base, Env{Prefix: "APP"})
+	lines := strings.Split(conf.Explain("server", "port"), "\n")
+	if len(lines) != 2 || lines[0] != `server.port = "8081" (env APP_SERVER_PORT)` ||
+		lines[1] != "\toverrides \"8080\" ("+base+":2)" {
+		t.Errorf("[Error]TestExplain %q", lines)
+	}
+	conf.SetValue("server", "port", "1")
+	if s := conf.Explain("server", "port"); !strings.HasPrefix(s, `server.port = "1" (SetValue)`) {
+		t.Errorf("[Error]TestExplain SetValue %s", s)
+	}
+	if s := conf.Explain("server", "none"); s != "server.none is not set" {
+		t.Errorf("[Error]TestExplain %s", s)
+	}
+	if s := SetConfig("init.ini").Explain("", "app_mode"); !strings.HasSuffix(s, "(init.ini:2)") {
+		t.Errorf("[Error]TestExplain SetConfig %s", s)
+	}
+}
+
+func BenchmarkLoad(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Load("init.ini", "init.ini", Env{Prefix: "APP"})
+	}
+}
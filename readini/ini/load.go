@@ -0,0 +1,47 @@
+package ini
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+)
+
+//ErrNotFound : 配置文件不存在，和 fs.ErrNotExist 相同，用 errors.Is 判断
+var ErrNotFound = fs.ErrNotExist
+
+var errNoFile = errors.New("[Error]the config is not read from a file, use SaveTo")
+
+//FSFile : Load的一个来源，fs.FS中的一个文件，例如用embed.FS嵌入程序的默认配置；
+//引用的文件也从FS中读取
+type FSFile struct {
+	FS   fs.FS
+	Path string
+}
+
+//LoadBytes ：使用默认选项从data读取配置
+func LoadBytes(data []byte) (*Config, error) {
+	return LoadOptions{}.LoadBytes(data)
+}
+
+//LoadFS ：使用默认选项从fsys中读取配置文件
+func LoadFS(fsys fs.FS, path string) (*Config, error) {
+	return LoadOptions{}.LoadFS(fsys, path)
+}
+
+//LoadBytes ：从data读取配置
+func (opts LoadOptions) LoadBytes(data []byte) (*Config, error) {
+	return opts.Load(data)
+}
+
+//LoadFS ：从fsys中读取配置文件
+func (opts LoadOptions) LoadFS(fsys fs.FS, path string) (*Config, error) {
+	return opts.Load(FSFile{FS: fsys, Path: path})
+}
+
+// fsys为nil时从操作系统的文件系统读取
+func readFile(fsys fs.FS, name string) ([]byte, error) {
+	if fsys == nil {
+		return os.ReadFile(name)
+	}
+	return fs.ReadFile(fsys, name)
+}
@@ -0,0 +1,96 @@
+package ini
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadReader(t *testing.T) {
+	conf, err := Load(strings.NewReader("a = 1\n[s]\nb = 2\n"))
+	if err != nil {
+		t.Fatalf("[Error]TestLoadReader %v", err)
+	}
+	conf.Reload()
+	if v, _ := conf.GetValue("s", "b"); v != "2" {
+		t.Errorf("[Error]TestLoadReader %q", v)
+	}
+	if s := conf.Explain("", "a"); s != `a = "1" (<bytes>:1)` {
+		t.Errorf("[Error]TestLoadReader Explain %s", s)
+	}
+	if err := conf.Save(); err == nil {
+		t.Errorf("[Error]TestLoadReader Save should fail")
+	}
+	var buf bytes.Buffer
+	conf.WriteTo(&buf)
+	if buf.String() != "a = 1\n[s]\nb = 2\n" {
+		t.Errorf("[Error]TestLoadReader WriteTo %q", buf.String())
+	}
+}
+
+func TestLoadBytes(t *testing.T) {
+	conf, err := LoadBytes([]byte("a = 1\n"))
+	if v, _ := conf.GetValue("", "a"); err != nil || v != "1" {
+		t.Errorf("[Error]TestLoadBytes %v", err)
+	}
+	_, err = LoadBytes([]byte("a = 1\n[s"))
+	var pe *ParseError
+	if !errors.As(err, &pe) || pe.Line != 2 || pe.Col != 3 || pe.File != "" {
+		t.Errorf("[Error]TestLoadBytes %v", err)
+	}
+}
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"conf/app.ini":  {Data: []byte("a = 1\n[db]\n!include db.ini\n")},
+		"conf/db.ini":   {Data: []byte("host = localhost\n")},
+		"conf/bad.ini":  {Data: []byte("[db\n")},
+		"conf/loop.ini": {Data: []byte("!include ./loop.ini\n")},
+	}
+	conf, err := LoadFS(fsys, "conf/app.ini")
+	if err != nil {
+		t.Fatalf("[Error]TestLoadFS %v", err)
+	}
+	if v, _ := conf.GetValue("db", "host"); v != "localhost" {
+		t.Errorf("[Error]TestLoadFS %q", v)
+	}
+
+	// 嵌入的默认配置被文件覆盖
+	path := tempConfig(t, "[db]\nhost = example.com\n")
+	conf, _ = Load(FSFile{fsys, "conf/app.ini"}, path)
+	if v, _ := conf.GetValue("db", "host"); v != "example.com" {
+		t.Errorf("[Error]TestLoadFS override %q", v)
+	}
+
+	_, err = LoadFS(fsys, "conf/bad.ini")
+	var pe *ParseError
+	if !errors.As(err, &pe) || pe.File != "conf/bad.ini" || pe.Line != 1 ||
+		err.Error() != "[Error]conf/bad.ini: line 1, col 4: missing ]" {
+		t.Errorf("[Error]TestLoadFS %v", err)
+	}
+	if _, err = LoadFS(fsys, "conf/loop.ini"); err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Errorf("[Error]TestLoadFS cycle %v", err)
+	}
+}
+
+func TestErrNotFound(t *testing.T) {
+	if _, err := Load("not-exist.ini"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("[Error]TestErrNotFound %v", err)
+	}
+	if _, err := LoadFS(fstest.MapFS{}, "app.ini"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("[Error]TestErrNotFound FS %v", err)
+	}
+	conf := SetConfig("not-exist.ini")
+	if _, err := conf.GetValue("", "a"); err == nil {
+		t.Errorf("[Error]TestErrNotFound SetConfig")
+	}
+}
+
+func BenchmarkLoadBytes(b *testing.B) {
+	data := []byte("a = 1\n[s]\nb = 2\nc = ${b}\n")
+	for i := 0; i < b.N; i++ {
+		LoadBytes(data)
+	}
+}
@@ -0,0 +1,114 @@
+package ini
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+var errNotStruct = errors.New("[Error]MapTo and ReflectFrom need a pointer to struct")
+
+// 结构体字段对应的名字，ini:"-" 表示忽略
+func fieldName(f reflect.StructField) (string, bool) {
+	if f.PkgPath != "" {
+		return "", false // 未导出
+	}
+	name := f.Name
+	if tag, ok := f.Tag.Lookup("ini"); ok {
+		if tag == "-" {
+			return "", false
+		}
+		if tag = strings.TrimSpace(tag); tag != "" {
+			name = tag
+		}
+	}
+	return name, true
+}
+
+// 结构体类型的字段是一个section，其它字段和能从文本解析的结构体（如time.Time）是key
+func isSection(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !reflect.PtrTo(t).Implements(typeTextUnmarshaler)
+}
+
+func structOf(v interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, errNotStruct
+	}
+	return rv.Elem(), nil
+}
+
+//MapTo ：把配置绑定到结构体，字段用 ini:"name" 指定名字；结构体字段对应一个section，
+//其它字段对应默认section中的key；没有的key不改变字段的值
+func (c *Config) MapTo(v interface{}) error {
+	rv, err := structOf(v)
+	if err != nil {
+		return err
+	}
+	return c.mapSection("", rv, true)
+}
+
+func (c *Config) mapSection(sec string, rv reflect.Value, top bool) error {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, ok := fieldName(t.Field(i))
+		if !ok {
+			continue
+		}
+		fv := rv.Field(i)
+		if top && isSection(fv.Type()) {
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					fv.Set(reflect.New(fv.Type().Elem()))
+				}
+				fv = fv.Elem()
+			}
+			if err := c.mapSection(name, fv, false); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := c.getAs(sec, name, fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//ReflectFrom ：把结构体的值写入配置，是MapTo的反向操作
+func (c *Config) ReflectFrom(v interface{}) error {
+	rv, err := structOf(v)
+	if err != nil {
+		return err
+	}
+	c.reflectSection("", rv, true)
+	return nil
+}
+
+func (c *Config) reflectSection(sec string, rv reflect.Value, top bool) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, ok := fieldName(t.Field(i))
+		if !ok {
+			continue
+		}
+		fv := rv.Field(i)
+		if top && isSection(fv.Type()) {
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			c.reflectSection(name, fv, false)
+			continue
+		}
+		if fv.Kind() == reflect.Ptr && fv.IsNil() {
+			continue
+		}
+		c.SetValue(sec, name, formatField(fv))
+	}
+}
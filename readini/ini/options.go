@@ -0,0 +1,54 @@
+package ini
+
+import "strings"
+
+//DuplicatePolicy : 同一个section中出现重复的key时的处理方式
+type DuplicatePolicy int
+
+const (
+	//DuplicateLastWins : 后面的值覆盖前面的值
+	DuplicateLastWins DuplicatePolicy = iota
+	//DuplicateError : 重复的key是一个解析错误
+	DuplicateError
+	//DuplicateFirstWins : 保留第一个值，忽略后面的值
+	DuplicateFirstWins
+	//DuplicateAccumulate : 保留所有的值，GetValues返回所有的值，GetValue返回最后一个值
+	DuplicateAccumulate
+)
+
+//SectionPolicy : 同一个文件中出现重复的section时的处理方式
+type SectionPolicy int
+
+const (
+	//SectionMerge : 合并重复的section，其中重复的key按DuplicatePolicy处理
+	SectionMerge SectionPolicy = iota
+	//SectionError : 重复的section是一个解析错误
+	SectionError
+	//SectionReplace : 后面的section替换前面的同名section
+	SectionReplace
+)
+
+//LoadOptions : 解析配置文件的选项，零值接受 "#" 和 ";" 注释、区分大小写、重复的key以最后一个为准、
+//合并重复的section、展开值中的引用；展开引用时值中的 "$" 和 "%" 要写成 "$$" 和 "%%"，保存时会自动转义
+type LoadOptions struct {
+	CommentPrefixes   []string        //整行注释和行内注释的前缀，为空时是 "#" 和 ";"
+	Insensitive       bool            //section和key不区分大小写，名字都转换为小写
+	Duplicate         DuplicatePolicy //重复的key的处理方式
+	DuplicateSections SectionPolicy   //重复的section的处理方式
+	NoInterpolation   bool            //不展开值中的 %(key)s、${section.key} 和 ${ENV:VAR}
+}
+
+//SetConfig ：使用这些选项初始化一个设置文件，读取失败时返回一个空的配置，需要错误时使用Load
+func (opts LoadOptions) SetConfig(filepath string) *Config {
+	conf := &Config{filepath: filepath, opts: opts}
+	conf.Reload()
+	return conf
+}
+
+// 按选项转换section或key的名字
+func (opts LoadOptions) name(s string) string {
+	if opts.Insensitive {
+		return strings.ToLower(s)
+	}
+	return s
+}
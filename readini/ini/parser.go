@@ -0,0 +1,344 @@
+package ini
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// 配置文件的语法（EBNF），每行首尾的空白被忽略：
+//
+//	file     = { line } .
+//	line     = blank | comment | include | section | entry .
+//	include  = "!include" space path .
+//	comment  = prefix { char } .
+//	section  = "[" name "]" [ inline ] .
+//	entry    = key [ ( "=" | ":" ) value ] [ inline ] .
+//	inline   = space comment .
+//	value    = quoted | { char | escape } [ "\" newline value ] .
+//	quoted   = `"` { char | escape | qescape } `"` | "'" { char } "'" .
+//	escape   = "\" ( "\" | prefix ) .
+//	qescape  = "\" ( `"` | "'" | "n" | "t" | "r" ) .
+//
+// prefix 是 LoadOptions.CommentPrefixes 中的注释前缀，默认是 "#" 和 ";"。
+// key 取第一个 "=" 或 ":" 之前的内容；没有分隔符的行是一个没有值的 key。
+// 行内注释必须以空白和注释前缀开始，引号中的内容不会被当作注释。
+// 未加引号的值以 "\" 结尾时，下一行（去掉行首空白）接在它后面。
+// 未加引号的值中其它的 "\" 原样保留，因此 Windows 路径不需要转义，"\n" 等只在双引号中转义；
+// 路径结尾的 "\" 和路径中的 "\\" 要写成 "\\" 和 "\\\\"，或者把路径放在单引号中。
+// "!include path" 和名为 include 的 key 引用另一个文件，相对路径相对于当前文件所在的目录，
+// 读取时被引用文件默认section中的key加入当前section。
+
+//ParseError : 解析错误及其所在的行和列，行列都从1开始，列按字符计算
+type ParseError struct {
+	File string // 不是从文件读取时为空
+	Line int
+	Col  int
+	Msg  string
+}
+
+func (e *ParseError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("[Error]%s: line %d, col %d: %s", e.File, e.Line, e.Col, e.Msg)
+	}
+	return fmt.Sprintf("[Error]line %d, col %d: %s", e.Line, e.Col, e.Msg)
+}
+
+// 解析后的配置文件，保留注释和顺序
+type document struct {
+	sections []*section // 第一个是没有名字的默认section
+	crlf     bool       // 文件使用 "\r\n" 换行
+}
+
+type section struct {
+	name    string
+	line    int
+	raw     string // section所在的行，默认section为空
+	items   []*item
+	replace bool // 替换前面的同名section
+}
+
+// 一个配置项，或者一个空行或注释行
+type item struct {
+	line     int
+	raw      []string // 原始的行，续行时有多行
+	comment  bool
+	key      string
+	value    string
+	hasValue bool
+	start    int // 值在第一行中的起止位置，用于改写值时保留其它内容
+	end      int
+	dirty    bool   // 值被修改过，保存时需要改写
+	include  string // include指令引用的文件，这时comment为true
+	dup      bool   // 同一个文件中前面已经有这个key
+}
+
+// 列号，按字符计算
+func column(l string, i int) int {
+	return utf8.RuneCountInString(l[:i]) + 1
+}
+
+type parser struct {
+	opts     LoadOptions
+	prefixes []string
+	keys     map[string]map[string]bool // 已经出现的key
+	sections map[string]bool            // 已经出现的section
+}
+
+func parse(r io.Reader, opts LoadOptions) (*document, error) {
+	p := &parser{opts: opts, prefixes: opts.CommentPrefixes, keys: make(map[string]map[string]bool), sections: make(map[string]bool)}
+	if len(p.prefixes) == 0 {
+		p.prefixes = []string{"#", ";"}
+	}
+	return p.parse(r)
+}
+
+func (p *parser) parse(r io.Reader) (*document, error) {
+	sec := &section{}
+	doc := &document{sections: []*section{sec}}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	sc.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		if advance > 1 && data[advance-1] == '\n' && data[advance-2] == '\r' {
+			doc.crlf = true
+		}
+		return advance, token, err
+	})
+	n := 0
+	for sc.Scan() {
+		n++
+		raw := sc.Text()
+		if n == 1 {
+			raw = strings.TrimPrefix(raw, "\ufeff") // UTF-8 BOM
+		}
+		l := strings.TrimSpace(raw)
+		indent := len(raw) - len(strings.TrimLeft(raw, " \t"))
+
+		switch {
+		case len(l) == 0 || p.isComment(l):
+			sec.items = append(sec.items, &item{line: n, raw: []string{raw}, comment: true})
+		case strings.HasPrefix(l, "!include") && (len(l) == len("!include") || l[len("!include")] == ' ' || l[len("!include")] == '\t'):
+			path := strings.TrimSpace(l[len("!include"):])
+			if path == "" {
+				return nil, &ParseError{Line: n, Col: column(raw, indent), Msg: "missing include path"}
+			}
+			sec.items = append(sec.items, &item{line: n, raw: []string{raw}, comment: true, include: path})
+		case l[0] == '[':
+			name, err := p.parseSection(l)
+			if err != nil {
+				err.Line, err.Col = n, err.Col+column(raw, indent)-1
+				return nil, err
+			}
+			sec = &section{name: name, line: n, raw: raw}
+			if p.sections[name] {
+				switch p.opts.DuplicateSections {
+				case SectionError:
+					return nil, &ParseError{Line: n, Col: column(raw, indent), Msg: fmt.Sprintf("duplicate section %q", name)}
+				case SectionReplace:
+					delete(p.keys, name)
+					sec.replace = true
+				}
+			}
+			p.sections[name] = true
+			doc.sections = append(doc.sections, sec)
+		default:
+			it := &item{line: n, raw: []string{raw}}
+			offset := column(raw, indent) - 1
+			for {
+				more, err := p.parseEntry(l, it)
+				if err != nil {
+					err.Line, err.Col = n, err.Col+offset
+					return nil, err
+				}
+				if !more || !sc.Scan() {
+					break
+				}
+				n++
+				raw = sc.Text()
+				it.raw = append(it.raw, raw)
+				l = strings.TrimLeft(raw, " \t")
+				offset = column(raw, len(raw)-len(l)) - 1
+				l = strings.TrimRight(l, " \t")
+			}
+			if it.hasValue {
+				it.start, it.end = it.start+indent, it.end+indent
+			}
+			if it.key == p.name("include") {
+				if it.value == "" {
+					return nil, &ParseError{Line: it.line, Col: column(it.raw[0], indent), Msg: "missing include path"}
+				}
+				it.comment, it.include = true, it.value
+			} else if err := p.checkDuplicate(sec.name, it); err != nil {
+				return nil, err
+			}
+			sec.items = append(sec.items, it)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func (p *parser) parseSection(l string) (string, *ParseError) {
+	end := strings.IndexByte(l, ']')
+	if end < 0 {
+		return "", &ParseError{Col: column(l, len(l)), Msg: "missing ]"}
+	}
+	name := p.name(l[1:end])
+	if name == "" {
+		return "", &ParseError{Col: 1, Msg: "empty section name"}
+	}
+	if rest := strings.TrimSpace(l[end+1:]); rest != "" && !p.isComment(rest) {
+		return "", &ParseError{Col: column(l, end+1), Msg: "unexpected text after section"}
+	}
+	return name, nil
+}
+
+// 解析一行（或一个续行）到it，more表示值在下一行继续
+func (p *parser) parseEntry(l string, it *item) (more bool, err *ParseError) {
+	i := 0
+	if !it.hasValue && it.key == "" {
+		i = strings.IndexAny(l, "=:")
+		key := l
+		if i >= 0 {
+			key = l[:i]
+		}
+		if j := p.inlineComment(key); j >= 0 {
+			key, i = key[:j], -1
+		}
+		it.key = p.name(key)
+		if it.key == "" {
+			return false, &ParseError{Col: 1, Msg: "missing key"}
+		}
+		if i < 0 {
+			return false, nil
+		}
+		it.hasValue = true
+		i++
+		for i < len(l) && (l[i] == ' ' || l[i] == '\t') {
+			i++
+		}
+		it.start, it.end = i, i
+	}
+	first := len(it.raw) == 1
+
+	if i < len(l) && (l[i] == '"' || l[i] == '\'') && it.value == "" {
+		return false, p.parseQuoted(l, i, it)
+	}
+
+	var b strings.Builder
+	b.WriteString(it.value)
+	for ; i < len(l); i++ {
+		c := l[i]
+		if p.inlineCommentAt(l, i) {
+			break
+		}
+		if c != '\\' {
+			b.WriteByte(c)
+		} else if i+1 == len(l) {
+			it.value = b.String()
+			return true, nil
+		} else {
+			i++
+			b.WriteString(p.unescape(l[i], false))
+		}
+		if first && c != ' ' && c != '\t' {
+			it.end = i + 1
+		}
+	}
+	it.value = strings.TrimRight(b.String(), " \t")
+	return false, nil
+}
+
+func (p *parser) parseQuoted(l string, start int, it *item) *ParseError {
+	quote := l[start]
+	var b strings.Builder
+	i := start + 1
+	for ; i < len(l) && l[i] != quote; i++ {
+		if l[i] == '\\' && quote == '"' && i+1 < len(l) {
+			i++
+			b.WriteString(p.unescape(l[i], true))
+			continue
+		}
+		b.WriteByte(l[i])
+	}
+	if i == len(l) {
+		return &ParseError{Col: column(l, start), Msg: "unterminated quoted value"}
+	}
+	if rest := strings.TrimSpace(l[i+1:]); rest != "" && !p.isComment(rest) {
+		return &ParseError{Col: column(l, i+1), Msg: "unexpected text after quoted value"}
+	}
+	if len(it.raw) == 1 {
+		it.end = i + 1
+	}
+	it.value = b.String()
+	return nil
+}
+
+// 转义字符的值，quoted表示在双引号中；不认识的转义保留 "\"
+func (p *parser) unescape(c byte, quoted bool) string {
+	switch {
+	case c == '\\':
+		return string(c)
+	case !quoted:
+	case c == 'n':
+		return "\n"
+	case c == 't':
+		return "\t"
+	case c == 'r':
+		return "\r"
+	case c == '"' || c == '\'':
+		return string(c)
+	}
+	for _, prefix := range p.prefixes {
+		if prefix[0] == c {
+			return string(c)
+		}
+	}
+	return "\\" + string(c)
+}
+
+func (p *parser) isComment(s string) bool {
+	for _, prefix := range p.prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// s[i:]是否是一个行内注释
+func (p *parser) inlineCommentAt(s string, i int) bool {
+	return i > 0 && (s[i-1] == ' ' || s[i-1] == '\t') && p.isComment(s[i:])
+}
+
+// 行内注释的位置，没有时返回-1
+func (p *parser) inlineComment(s string) int {
+	for i := 1; i < len(s); i++ {
+		if p.inlineCommentAt(s, i) {
+			return i
+		}
+	}
+	return -1
+}
+
+// section或key的名字
+func (p *parser) name(s string) string {
+	return p.opts.name(strings.TrimSpace(s))
+}
+
+func (p *parser) checkDuplicate(sec string, it *item) *ParseError {
+	if p.keys[sec] == nil {
+		p.keys[sec] = make(map[string]bool)
+	}
+	if p.keys[sec][it.key] && p.opts.Duplicate == DuplicateError {
+		return &ParseError{Line: it.line, Col: 1, Msg: fmt.Sprintf("duplicate key %q", it.key)}
+	}
+	it.dup = p.keys[sec][it.key]
+	p.keys[sec][it.key] = true
+	return nil
+}
@@ -0,0 +1,207 @@
+package ini
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	src := `; top comment
+name = plain value ; inline note
+url: http://example.com/#anchor
+quoted = "  spaced ; not a comment  "   # note
+single = 'C:\dir\n'
+escaped = a\;b \# c\\
+path = C:\dir\file
+win = C:\temp\new\table
+multi = first \
+	second
+flag
+
+[server]   ; section note
+port=8080
+empty =
+`
+	doc, err := parse(strings.NewReader(src), LoadOptions{})
+	if err != nil {
+		t.Fatalf("[Error]TestParse %v", err)
+	}
+	expected := map[string]string{
+		"name":    "plain value",
+		"url":     "http://example.com/#anchor",
+		"quoted":  "  spaced ; not a comment  ",
+		"single":  `C:\dir\n`,
+		"escaped": `a;b # c\`,
+		"path":    `C:\dir\file`,
+		"win":     `C:\temp\new\table`,
+		"multi":   "first second",
+		"flag":    "",
+	}
+	if len(doc.sections) != 2 || doc.sections[1].name != "server" || doc.sections[1].line != 13 {
+		t.Fatalf("[Error]TestParse sections")
+	}
+	keys := 0
+	for _, it := range doc.sections[0].items {
+		if it.comment {
+			continue
+		}
+		keys++
+		if v, ok := expected[it.key]; !ok || v != it.value {
+			t.Errorf("[Error]TestParse %s = %q", it.key, it.value)
+		}
+		if it.key == "flag" && it.hasValue {
+			t.Errorf("[Error]TestParse key without value")
+		}
+	}
+	if keys != len(expected) {
+		t.Errorf("[Error]TestParse %d keys", keys)
+	}
+	if it := doc.sections[0].items[8]; it.key != "multi" || len(it.raw) != 2 || it.line != 9 {
+		t.Errorf("[Error]TestParse continuation line")
+	}
+	if it := doc.sections[1].items[1]; it.key != "empty" || !it.hasValue || it.value != "" {
+		t.Errorf("[Error]TestParse empty value")
+	}
+}
+
+func TestParseError(t *testing.T) {
+	cases := []struct {
+		src       string
+		line, col int
+	}{
+		{"a = 1\n[server", 2, 8},
+		{"[ ]", 1, 1},
+		{"a = 1\n\n  = 2", 3, 3},
+		{`a = "open`, 1, 5},
+		{`a = "v" x`, 1, 8},
+		{"中文 = 'x' y", 1, 9},
+		{"[s] x", 1, 4},
+		{"a = 1\n  !include", 2, 3},
+		{"[s]\ninclude =", 2, 1},
+	}
+	for _, c := range cases {
+		_, err := parse(strings.NewReader(c.src), LoadOptions{})
+		e, ok := err.(*ParseError)
+		if !ok || e.Line != c.line || e.Col != c.col {
+			t.Errorf("[Error]TestParseError %q: %v", c.src, err)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	src := strings.Repeat("[section]\nkey = \"value\" ; comment\nother: a \\\n  b\n", 100)
+	for i := 0; i < b.N; i++ {
+		parse(strings.NewReader(src), LoadOptions{})
+	}
+}
+
+func TestLoadOptions(t *testing.T) {
+	src := "# hash\n; semicolon\nA = 1 ; note\n[Server]\nPort = 80 // note\n"
+	doc, err := parse(strings.NewReader(src), LoadOptions{})
+	if err != nil || len(doc.sections[0].items) != 3 || doc.sections[0].items[2].value != "1" {
+		t.Errorf("[Error]TestLoadOptions default comments")
+	}
+
+	opts := LoadOptions{CommentPrefixes: []string{"//", ";"}, Insensitive: true}
+	doc, err = parse(strings.NewReader(src), opts)
+	if err != nil {
+		t.Fatalf("[Error]TestLoadOptions %v", err)
+	}
+	if it := doc.sections[0].items[0]; it.comment || it.key != "# hash" {
+		t.Errorf("[Error]TestLoadOptions comment prefixes")
+	}
+	if sec := doc.sections[1]; sec.name != "server" || sec.items[0].key != "port" || sec.items[0].value != "80" {
+		t.Errorf("[Error]TestLoadOptions insensitive")
+	}
+
+	_, err = parse(strings.NewReader("a = 1\n[s]\na = 2\n[s]\na = 3\n"), LoadOptions{Duplicate: DuplicateError})
+	if e, ok := err.(*ParseError); !ok || e.Line != 5 {
+		t.Errorf("[Error]TestLoadOptions duplicate %v", err)
+	}
+}
+
+const duplicateConfig = `[s]
+a = 1
+b = x
+a = 2
+[t]
+c = 1
+[s]
+a = 3
+d = ${b}%(b)s
+`
+
+func TestDuplicateKeys(t *testing.T) {
+	for _, tt := range []struct {
+		policy DuplicatePolicy
+		value  string
+		values []string
+	}{
+		{DuplicateLastWins, "3", []string{"3"}},
+		{DuplicateFirstWins, "1", []string{"1"}},
+		{DuplicateAccumulate, "3", []string{"1", "2", "3"}},
+	} {
+		conf, err := LoadOptions{Duplicate: tt.policy}.LoadBytes([]byte(duplicateConfig))
+		if err != nil {
+			t.Fatalf("[Error]TestDuplicateKeys %v", err)
+		}
+		if v, _ := conf.GetValue("s", "a"); v != tt.value {
+			t.Errorf("[Error]TestDuplicateKeys %d GetValue %q", tt.policy, v)
+		}
+		if v := conf.GetValues("s", "a"); !reflect.DeepEqual(v, tt.values) {
+			t.Errorf("[Error]TestDuplicateKeys %d GetValues %q", tt.policy, v)
+		}
+		if v, _ := conf.GetValue("s", "d"); v != "xx" {
+			t.Errorf("[Error]TestDuplicateKeys %d merge %q", tt.policy, v)
+		}
+	}
+	if conf := SetConfig(""); conf.GetValues("s", "a") != nil {
+		t.Errorf("[Error]TestDuplicateKeys GetValues missing")
+	}
+
+	conf, _ := LoadOptions{Duplicate: DuplicateAccumulate}.LoadBytes([]byte("[s]\na = ${b}\nb = 1\na = 2$$\n[s.c]\n"))
+	if v := conf.GetValues("s.c", "a"); !reflect.DeepEqual(v, []string{"1", "2$"}) {
+		t.Errorf("[Error]TestDuplicateKeys interpolate %q", v)
+	}
+	conf.SetValue("s", "a", "3")
+	if v := conf.GetValues("s", "a"); !reflect.DeepEqual(v, []string{"3"}) {
+		t.Errorf("[Error]TestDuplicateKeys SetValue %q", v)
+	}
+	var buf bytes.Buffer
+	conf.WriteTo(&buf)
+	if buf.String() != "[s]\nb = 1\na = 3\n[s.c]\n" {
+		t.Errorf("[Error]TestDuplicateKeys WriteTo %q", buf.String())
+	}
+
+	conf, _ = LoadOptions{Duplicate: DuplicateFirstWins}.LoadBytes([]byte(duplicateConfig))
+	conf.SetValue("s", "a", "4")
+	buf.Reset()
+	conf.WriteTo(&buf)
+	if !strings.Contains(buf.String(), "[s]\na = 4\nb = x\na = 2\n") {
+		t.Errorf("[Error]TestDuplicateKeys FirstWins WriteTo %q", buf.String())
+	}
+}
+
+func TestDuplicateSections(t *testing.T) {
+	_, err := LoadOptions{DuplicateSections: SectionError}.LoadBytes([]byte(duplicateConfig))
+	if e, ok := err.(*ParseError); !ok || e.Line != 7 || !strings.Contains(e.Msg, `duplicate section "s"`) {
+		t.Errorf("[Error]TestDuplicateSections error %v", err)
+	}
+
+	opts := LoadOptions{DuplicateSections: SectionReplace, Duplicate: DuplicateError}
+	conf, err := opts.LoadBytes([]byte("[s]\na = 1\nb = 2\n[s]\na = 3\n"))
+	if err != nil {
+		t.Fatalf("[Error]TestDuplicateSections %v", err)
+	}
+	if v, _ := conf.GetValue("s", "a"); v != "3" || conf.HasKey("s", "b") {
+		t.Errorf("[Error]TestDuplicateSections replace %q", v)
+	}
+	conf.SetValue("s", "a", "4")
+	var buf bytes.Buffer
+	conf.WriteTo(&buf)
+	if buf.String() != "[s]\na = 1\nb = 2\n[s]\na = 4\n" {
+		t.Errorf("[Error]TestDuplicateSections WriteTo %q", buf.String())
+	}
+}
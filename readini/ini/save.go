@@ -0,0 +1,243 @@
+package ini
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//WriteTo ：把配置写到w中，保留原文件的注释、空行和顺序，只改写被修改的值
+func (c *Config) WriteTo(w io.Writer) (int64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var buf bytes.Buffer
+	newline := "\n"
+	doc := c.document()
+	if doc.crlf {
+		newline = "\r\n"
+	}
+	blank := true // 上一行是空行
+	writeLine := func(l string) {
+		buf.WriteString(l)
+		buf.WriteString(newline)
+		blank = strings.TrimSpace(l) == ""
+	}
+	for _, sec := range doc.sections {
+		switch {
+		case sec.raw != "":
+			writeLine(sec.raw)
+		case sec.name != "":
+			// 新加的section和前面的内容隔一个空行
+			if !blank {
+				writeLine("")
+			}
+			writeLine("[" + sec.name + "]")
+		}
+		for _, it := range sec.items {
+			switch {
+			case !it.dirty:
+				for _, l := range it.raw {
+					writeLine(l)
+				}
+			case it.raw != nil && it.start > 0:
+				l := it.raw[0][:it.start] + c.formatValue(it.value)
+				if len(it.raw) == 1 {
+					l += it.raw[0][it.end:]
+				}
+				writeLine(l)
+			default:
+				writeLine(it.key + " = " + c.formatValue(it.value))
+			}
+		}
+	}
+	return buf.WriteTo(w)
+}
+
+//SaveTo ：把配置保存到文件，先写临时文件再改名，不会留下写了一半的文件
+func (c *Config) SaveTo(path string) error {
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := os.CreateTemp(dir, "."+base+".tmp*")
+	if err != nil {
+		return err
+	}
+	_, err = c.WriteTo(tmp)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	mode := os.FileMode(0644)
+	if fi, serr := os.Stat(path); serr == nil {
+		mode = fi.Mode()
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), mode)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+//Save ：把配置保存回读取它的文件
+func (c *Config) Save() error {
+	if c.filepath == "" {
+		return errNoFile
+	}
+	return c.SaveTo(c.filepath)
+}
+
+// 只有一个空的默认section
+func newDocument() *document {
+	return &document{sections: []*section{{}}}
+}
+
+// 读到的文件，没有时是一个空文件，c.mu必须被持有
+func (c *Config) document() *document {
+	if c.doc == nil {
+		return newDocument()
+	}
+	return c.doc
+}
+
+// 设置key的值，key不存在时加在section最后一个key之后，section不存在时加在文件末尾；
+// 重复的key改写读取时生效的那一个，DuplicateAccumulate时删除其它的
+func (d *document) set(name, key, value string, policy DuplicatePolicy) {
+	var target *section
+	var found *item
+	for _, sec := range d.sections {
+		if sec.name != name {
+			continue
+		}
+		if sec.replace {
+			found = nil
+		}
+		target = sec
+		for _, it := range sec.items {
+			if !it.comment && it.key == key && (found == nil || policy != DuplicateFirstWins) {
+				found = it
+			}
+		}
+	}
+	if found != nil && policy == DuplicateAccumulate {
+		for _, sec := range d.sections {
+			if sec.name != name {
+				continue
+			}
+			items := sec.items[:0]
+			for _, it := range sec.items {
+				if it == found || it.comment || it.key != key {
+					items = append(items, it)
+				}
+			}
+			sec.items = items
+		}
+	}
+	if found != nil {
+		found.value, found.hasValue, found.dirty = value, true, true
+		return
+	}
+	if target == nil {
+		target = &section{name: name}
+		d.sections = append(d.sections, target)
+	}
+	i := len(target.items)
+	for i > 0 && target.items[i-1].comment {
+		i--
+	}
+	if i == 0 {
+		// 没有key时放在开头的注释之后，末尾的空行之前
+		for i = len(target.items); i > 0 && strings.TrimSpace(target.items[i-1].raw[0]) == ""; i-- {
+		}
+	}
+	it := &item{key: key, value: value, hasValue: true, dirty: true}
+	target.items = append(target.items[:i], append([]*item{it}, target.items[i:]...)...)
+}
+
+// 删除section中的key
+func (d *document) deleteKey(name, key string) {
+	for _, sec := range d.sections {
+		if sec.name != name {
+			continue
+		}
+		items := sec.items[:0]
+		for _, it := range sec.items {
+			if it.comment || it.key != key {
+				items = append(items, it)
+			}
+		}
+		sec.items = items
+	}
+}
+
+// 删除section，默认section只删除其中的key
+func (d *document) deleteSection(name string) {
+	sections := d.sections[:1]
+	for _, sec := range d.sections[1:] {
+		if sec.name != name {
+			sections = append(sections, sec)
+		}
+	}
+	d.sections = sections
+	if name == "" {
+		items := d.sections[0].items[:0]
+		for _, it := range d.sections[0].items {
+			if it.comment {
+				items = append(items, it)
+			}
+		}
+		d.sections[0].items = items
+	}
+}
+
+// 修改section的名字，保留section所在行的其它内容
+func (d *document) renameSection(old, new string) {
+	for _, sec := range d.sections[1:] {
+		if sec.name != old {
+			continue
+		}
+		sec.name = new
+		if i, j := strings.IndexByte(sec.raw, '['), strings.IndexByte(sec.raw, ']'); i >= 0 && j > i {
+			sec.raw = sec.raw[:i+1] + new + sec.raw[j:]
+		}
+	}
+}
+
+// 值的写法，必要时转义引用和加上引号，保证能被解析回同样的值
+func (c *Config) formatValue(v string) string {
+	if !c.opts.NoInterpolation {
+		v = strings.NewReplacer("$", "$$", "%", "%%").Replace(v)
+	}
+	prefixes := c.opts.CommentPrefixes
+	if len(prefixes) == 0 {
+		prefixes = []string{"#", ";"}
+	}
+	escapable := "\\" // 未加引号时的转义
+	for _, prefix := range prefixes {
+		escapable += prefix[:1]
+	}
+	quote := strings.TrimSpace(v) != v || strings.ContainsAny(v, "\n\r") || strings.HasPrefix(v, `"`) || strings.HasPrefix(v, "'")
+	for i := 0; i < len(v) && !quote; i++ {
+		if v[i] == '\\' {
+			quote = i+1 == len(v) || strings.IndexByte(escapable, v[i+1]) >= 0
+		} else if i > 0 && (v[i-1] == ' ' || v[i-1] == '\t') {
+			for _, prefix := range prefixes {
+				quote = quote || strings.HasPrefix(v[i:], prefix)
+			}
+		}
+	}
+	if !quote {
+		return v
+	}
+	r := strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n", "\r", "\\r", "\t", "\\t")
+	return "\"" + r.Replace(v) + "\""
+}
@@ -0,0 +1,104 @@
+package ini
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func tempConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "test.ini")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("[Error]%v", err)
+	}
+	return path
+}
+
+func TestWriteTo(t *testing.T) {
+	original, _ := os.ReadFile("init.ini")
+	conf := SetConfig("init.ini")
+	var buf bytes.Buffer
+	conf.WriteTo(&buf)
+	if strings.TrimRight(buf.String(), "\n") != strings.TrimRight(string(original), "\n") {
+		t.Errorf("[Error]TestWriteTo round trip")
+	}
+}
+
+func TestWriteToPaths(t *testing.T) {
+	values := []string{`C:\temp\new\table`, `C:\temp\`, `\\server\share`, "a\tb\nc", `a \; b`}
+	conf := FromMap(nil)
+	for i, v := range values {
+		conf.SetValue("paths", string(rune('a'+i)), v)
+	}
+	var buf bytes.Buffer
+	conf.WriteTo(&buf)
+	if !strings.Contains(buf.String(), "a = C:\\temp\\new\\table\n") {
+		t.Errorf("[Error]TestWriteToPaths quoted %q", buf.String())
+	}
+	loaded, err := LoadBytes(buf.Bytes())
+	if err != nil {
+		t.Fatalf("[Error]TestWriteToPaths %v", err)
+	}
+	for i, v := range values {
+		if got, _ := loaded.GetValue("paths", string(rune('a'+i))); got != v {
+			t.Errorf("[Error]TestWriteToPaths %q != %q", got, v)
+		}
+	}
+}
+
+func BenchmarkWriteTo(b *testing.B) {
+	conf := SetConfig("init.ini")
+	for i := 0; i < b.N; i++ {
+		conf.WriteTo(io.Discard)
+	}
+}
+
+func TestSave(t *testing.T) {
+	path := tempConfig(t, "# top\r\nname = old ; keep this note\r\n\r\n[server]\r\nport: 80\r\nmulti = a \\\r\n  b\r\n\r\n# trailing\r\n")
+	conf := SetConfig(path)
+	conf.SetValue("", "name", "new")
+	conf.SetValue("server", "port", " 8080 ")
+	conf.SetValue("server", "multi", "c")
+	conf.SetValue("server", "host", "a ; b")
+	conf.SetValue("paths", "data", `C:\data`)
+	if err := conf.Save(); err != nil {
+		t.Fatalf("[Error]TestSave %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	expected := "# top\r\nname = new ; keep this note\r\n\r\n[server]\r\nport: \" 8080 \"\r\nmulti = c\r\nhost = \"a ; b\"\r\n\r\n# trailing\r\n\r\n[paths]\r\ndata = C:\\data\r\n"
+	if string(data) != expected {
+		t.Errorf("[Error]TestSave got %q", data)
+	}
+	conf = SetConfig(path)
+	if v, _ := conf.GetValue("server", "port"); v != " 8080 " {
+		t.Errorf("[Error]TestSave reload %q", v)
+	}
+	if v, _ := conf.GetValue("server", "host"); v != "a ; b" {
+		t.Errorf("[Error]TestSave reload %q", v)
+	}
+	if files, _ := os.ReadDir(filepath.Dir(path)); len(files) != 1 {
+		t.Errorf("[Error]TestSave temp file left")
+	}
+}
+
+func TestSaveTo(t *testing.T) {
+	conf := SetConfig("init.ini")
+	conf.SetValue("server", "http_port", "80")
+	path := filepath.Join(t.TempDir(), "new.ini")
+	if err := conf.SaveTo(path); err != nil {
+		t.Fatalf("[Error]TestSaveTo %v", err)
+	}
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0644 {
+		t.Errorf("[Error]TestSaveTo mode")
+	}
+	if v, _ := SetConfig(path).GetValue("server", "http_port"); v != "80" {
+		t.Errorf("[Error]TestSaveTo %q", v)
+	}
+	if err := conf.SaveTo(filepath.Join(t.TempDir(), "no", "such.ini")); err == nil {
+		t.Errorf("[Error]TestSaveTo should fail")
+	}
+}
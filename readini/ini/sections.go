@@ -0,0 +1,216 @@
+package ini
+
+import (
+	"errors"
+	"strings"
+)
+
+// section和key的声明顺序
+type order struct {
+	sections []string
+	keys     map[string][]string // 存在的section都有一项
+}
+
+func (o *order) addSection(sec string) {
+	if o.keys == nil {
+		o.keys = make(map[string][]string)
+	}
+	if _, ok := o.keys[sec]; !ok {
+		o.sections = append(o.sections, sec)
+		o.keys[sec] = nil
+	}
+}
+
+func (o *order) add(sec, key string) {
+	o.addSection(sec)
+	for _, k := range o.keys[sec] {
+		if k == key {
+			return
+		}
+	}
+	o.keys[sec] = append(o.keys[sec], key)
+}
+
+func (o *order) remove(sec, key string) {
+	keys := o.keys[sec]
+	for i, k := range keys {
+		if k == key {
+			o.keys[sec] = append(keys[:i:i], keys[i+1:]...)
+			return
+		}
+	}
+}
+
+func (o *order) removeSection(sec string) {
+	for i, s := range o.sections {
+		if s == sec {
+			o.sections = append(o.sections[:i:i], o.sections[i+1:]...)
+			break
+		}
+	}
+	delete(o.keys, sec)
+}
+
+// [a.b] 的上级section是 [a]
+func parentOf(sec string) (string, bool) {
+	i := strings.LastIndexByte(sec, '.')
+	if i < 0 {
+		return "", false
+	}
+	return sec[:i], true
+}
+
+//Sections ：所有的section，按声明顺序排列，默认section有key时是 ""
+func (c *Config) Sections() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]string(nil), c.order.sections...)
+}
+
+//HasKey ：section中是否有key，包括从上级section继承的key
+func (c *Config) HasKey(section, key string) bool {
+	section, key = c.opts.name(section), c.opts.name(key)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if _, ok := c.data[section]; !ok {
+		return false
+	}
+	_, ok := c.get(section, key)
+	return ok
+}
+
+//DeleteKey ：删除section中的key，保存时从文件中删除；key不在这个section中时返回false
+func (c *Config) DeleteKey(section, key string) bool {
+	section, key = c.opts.name(section), c.opts.name(key)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.data[section][key]; !ok {
+		return false
+	}
+	delete(c.data[section], key)
+	delete(c.multi[section], key)
+	delete(c.origins, keyPath(section, key))
+	c.order.remove(section, key)
+	if c.doc != nil {
+		c.doc.deleteKey(section, key)
+	}
+	return true
+}
+
+//DeleteSection ：删除一个section和其中的注释，下级section不会被删除；section不存在时返回false
+func (c *Config) DeleteSection(name string) bool {
+	name = c.opts.name(name)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.data[name]; !ok {
+		return false
+	}
+	for key := range c.data[name] {
+		delete(c.origins, keyPath(name, key))
+	}
+	delete(c.data, name)
+	delete(c.multi, name)
+	c.order.removeSection(name)
+	if c.doc != nil {
+		c.doc.deleteSection(name)
+	}
+	return true
+}
+
+//RenameSection ：修改section的名字，保留它在文件中的位置和注释
+func (c *Config) RenameSection(old, new string) error {
+	old, new = c.opts.name(old), c.opts.name(new)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.data[old]; !ok || old == "" {
+		return errors.New(notFindValue)
+	}
+	if _, ok := c.data[new]; ok || new == "" {
+		return errors.New(keyNotUnique)
+	}
+	c.data[new] = c.data[old]
+	delete(c.data, old)
+	if multi, ok := c.multi[old]; ok {
+		c.multi[new] = multi
+		delete(c.multi, old)
+	}
+	for key := range c.data[new] {
+		if origins, ok := c.origins[keyPath(old, key)]; ok {
+			c.origins[keyPath(new, key)] = origins
+			delete(c.origins, keyPath(old, key))
+		}
+	}
+	for i, s := range c.order.sections {
+		if s == old {
+			c.order.sections[i] = new
+		}
+	}
+	c.order.keys[new] = c.order.keys[old]
+	delete(c.order.keys, old)
+	if c.doc != nil {
+		c.doc.renameSection(old, new)
+	}
+	return nil
+}
+
+//Section : 配置中的一个section
+type Section struct {
+	c    *Config
+	name string
+}
+
+//Section ：名为name的section，section不存在时Keys为空
+func (c *Config) Section(name string) *Section {
+	return &Section{c: c, name: c.opts.name(name)}
+}
+
+//Name ：section的名字
+func (s *Section) Name() string {
+	return s.name
+}
+
+//Keys ：section中的key，按声明顺序排列，之后是从上级section继承的key
+func (s *Section) Keys() []string {
+	c := s.c
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if _, ok := c.data[s.name]; !ok {
+		return nil
+	}
+	var keys []string
+	seen := make(map[string]bool)
+	for sec, ok := s.name, true; ok; sec, ok = parentOf(sec) {
+		for _, key := range c.order.keys[sec] {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+//HasKey ：section中是否有key，包括从上级section继承的key
+func (s *Section) HasKey(key string) bool {
+	return s.c.HasKey(s.name, key)
+}
+
+//Parent ：上级section，[a.b] 的上级是 [a]，顶层的section返回nil
+func (s *Section) Parent() *Section {
+	parent, ok := parentOf(s.name)
+	if !ok {
+		return nil
+	}
+	return &Section{c: s.c, name: parent}
+}
+
+//Children ：直接的下级section，按声明顺序排列
+func (s *Section) Children() []*Section {
+	var res []*Section
+	for _, name := range s.c.Sections() {
+		if parent, ok := parentOf(name); ok && parent == s.name {
+			res = append(res, &Section{c: s.c, name: name})
+		}
+	}
+	return res
+}
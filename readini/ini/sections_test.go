@@ -0,0 +1,89 @@
+package ini
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+const nestedConfig = `name = app
+[server]
+host = localhost
+port = 80
+[empty]
+[server.tls] ; 证书
+cert = a.pem
+port = 443
+[server.tls.client]
+verify = true
+[db]
+# 数据库
+host = db
+`
+
+func TestSections(t *testing.T) {
+	conf, _ := LoadBytes([]byte(nestedConfig))
+	if s := conf.Sections(); !reflect.DeepEqual(s, []string{"", "server", "empty", "server.tls", "server.tls.client", "db"}) {
+		t.Errorf("[Error]TestSections %q", s)
+	}
+	if keys := conf.Section("server.tls.client").Keys(); !reflect.DeepEqual(keys, []string{"verify", "cert", "port", "host"}) {
+		t.Errorf("[Error]TestSections Keys %q", keys)
+	}
+	if keys := conf.Section("empty").Keys(); len(keys) != 0 || conf.Section("none").Keys() != nil {
+		t.Errorf("[Error]TestSections empty %q", keys)
+	}
+	if v, _ := conf.GetValue("server.tls.client", "port"); v != "443" {
+		t.Errorf("[Error]TestSections inherited %q", v)
+	}
+	if v, _ := conf.GetInt("server.tls.client", "port", 0); v != 443 {
+		t.Errorf("[Error]TestSections GetInt %d", v)
+	}
+	if !conf.HasKey("server.tls", "host") || conf.HasKey("server", "cert") || conf.HasKey("none", "host") || !conf.Section("").HasKey("name") {
+		t.Errorf("[Error]TestSections HasKey")
+	}
+	tls := conf.Section("server.tls")
+	if tls.Parent().Name() != "server" || conf.Section("server").Parent() != nil {
+		t.Errorf("[Error]TestSections Parent")
+	}
+	if c := conf.Section("server").Children(); len(c) != 1 || c[0].Name() != "server.tls" {
+		t.Errorf("[Error]TestSections Children %v", c)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	conf, _ := LoadBytes([]byte(nestedConfig))
+	if !conf.DeleteKey("server", "port") || conf.DeleteKey("server", "port") || conf.DeleteKey("server.tls", "host") {
+		t.Errorf("[Error]TestDelete DeleteKey")
+	}
+	if !conf.DeleteSection("db") || conf.DeleteSection("db") || !conf.DeleteSection("empty") {
+		t.Errorf("[Error]TestDelete DeleteSection")
+	}
+	if err := conf.RenameSection("server.tls.client", "server.tls.peer"); err != nil {
+		t.Errorf("[Error]TestDelete RenameSection %v", err)
+	}
+	if conf.RenameSection("none", "x") == nil || conf.RenameSection("server", "server.tls") == nil {
+		t.Errorf("[Error]TestDelete RenameSection should fail")
+	}
+	if _, err := conf.GetValue("db", "host"); err == nil {
+		t.Errorf("[Error]TestDelete db")
+	}
+	if v, _ := conf.GetValue("server.tls.peer", "verify"); v != "true" {
+		t.Errorf("[Error]TestDelete renamed %q", v)
+	}
+	if s := conf.Sections(); !reflect.DeepEqual(s, []string{"", "server", "server.tls", "server.tls.peer"}) {
+		t.Errorf("[Error]TestDelete %q", s)
+	}
+	var buf bytes.Buffer
+	conf.WriteTo(&buf)
+	expected := "name = app\n[server]\nhost = localhost\n[server.tls] ; 证书\ncert = a.pem\nport = 443\n[server.tls.peer]\nverify = true\n"
+	if buf.String() != expected {
+		t.Errorf("[Error]TestDelete WriteTo %q", buf.String())
+	}
+}
+
+func BenchmarkSectionKeys(b *testing.B) {
+	conf, _ := LoadBytes([]byte(nestedConfig))
+	for i := 0; i < b.N; i++ {
+		conf.Section("server.tls.client").Keys()
+	}
+}
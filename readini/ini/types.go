@@ -0,0 +1,226 @@
+package ini
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//ValueError : 值不能转换为需要的类型，包含它在文件中的位置
+type ValueError struct {
+	File    string
+	Line    int
+	Section string
+	Key     string
+	Value   string
+	Err     error
+}
+
+func (e *ValueError) Error() string {
+	return fmt.Sprintf("[Error]%s:%d: [%s] %s = %q: %v", e.File, e.Line, e.Section, e.Key, e.Value, e.Err)
+}
+
+func (e *ValueError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	typeDuration        = reflect.TypeOf(time.Duration(0))
+	typeTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// 查找一个值，ok表示key存在
+func (c *Config) lookup(sec, key string) (value string, ok bool) {
+	sec, key = c.opts.name(sec), c.opts.name(key)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.get(sec, key)
+}
+
+// key所在的行，找不到时为0
+func (c *Config) line(sec, key string) int {
+	sec, key = c.opts.name(sec), c.opts.name(key)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	n := 0
+	for _, s := range c.document().sections {
+		if s.name != sec {
+			continue
+		}
+		for _, it := range s.items {
+			if !it.comment && it.key == key {
+				n = it.line
+			}
+		}
+	}
+	return n
+}
+
+// 把section中key的值转换到v中，key不存在时v不变
+func (c *Config) getAs(sec, key string, v reflect.Value) error {
+	s, ok := c.lookup(sec, key)
+	if !ok {
+		return nil
+	}
+	if err := setValue(v, s); err != nil {
+		file, line := c.position(c.opts.name(sec), c.opts.name(key))
+		return &ValueError{File: file, Line: line, Section: sec, Key: key, Value: s, Err: err}
+	}
+	return nil
+}
+
+//GetInt ：取一个整数，key不存在时返回def，不是整数时返回def和*ValueError
+func (c *Config) GetInt(section, key string, def int) (int, error) {
+	x := def
+	if err := c.getAs(section, key, reflect.ValueOf(&x).Elem()); err != nil {
+		return def, err
+	}
+	return x, nil
+}
+
+//GetFloat ：取一个浮点数，key不存在时返回def，不是数字时返回def和*ValueError
+func (c *Config) GetFloat(section, key string, def float64) (float64, error) {
+	x := def
+	if err := c.getAs(section, key, reflect.ValueOf(&x).Elem()); err != nil {
+		return def, err
+	}
+	return x, nil
+}
+
+//GetBool ：取一个布尔值，接受true/false、yes/no、on/off、1/0，key不存在时返回def
+func (c *Config) GetBool(section, key string, def bool) (bool, error) {
+	x := def
+	if err := c.getAs(section, key, reflect.ValueOf(&x).Elem()); err != nil {
+		return def, err
+	}
+	return x, nil
+}
+
+//GetDuration ：取一个time.ParseDuration格式的时间段，key不存在时返回def
+func (c *Config) GetDuration(section, key string, def time.Duration) (time.Duration, error) {
+	x := def
+	if err := c.getAs(section, key, reflect.ValueOf(&x).Elem()); err != nil {
+		return def, err
+	}
+	return x, nil
+}
+
+//GetStrings ：用sep分割一个值，去掉每一项首尾的空白，key不存在时返回def
+func (c *Config) GetStrings(section, key, sep string, def []string) []string {
+	s, ok := c.lookup(section, key)
+	if !ok {
+		return def
+	}
+	return splitValue(s, sep)
+}
+
+func splitValue(s, sep string) []string {
+	res := []string{}
+	if strings.TrimSpace(s) == "" {
+		return res
+	}
+	for _, x := range strings.Split(s, sep) {
+		res = append(res, strings.TrimSpace(x))
+	}
+	return res
+}
+
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "yes", "on":
+		return true, nil
+	case "no", "off":
+		return false, nil
+	}
+	return strconv.ParseBool(s)
+}
+
+// 把字符串转换为v的类型，切片的元素用 "," 分隔，实现了encoding.TextUnmarshaler的类型（如time.Time）用UnmarshalText
+func setValue(v reflect.Value, s string) error {
+	s = strings.TrimSpace(s)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setValue(v.Elem(), s)
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(typeTextUnmarshaler) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	if v.Type() == typeDuration {
+		d, err := time.ParseDuration(s)
+		if err == nil {
+			v.SetInt(int64(d))
+		}
+		return err
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := parseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		items := splitValue(s, ",")
+		res := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, x := range items {
+			if err := setValue(res.Index(i), x); err != nil {
+				return err
+			}
+		}
+		v.Set(res)
+	default:
+		return fmt.Errorf("unsupported type %v", v.Type())
+	}
+	return nil
+}
+
+// 字段v的字符串形式，与setValue相反
+func formatField(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		if text, err := m.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	if v.Type() == typeDuration {
+		return time.Duration(v.Int()).String()
+	}
+	if v.Kind() == reflect.Slice {
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = formatField(v.Index(i))
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
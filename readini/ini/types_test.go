@@ -0,0 +1,148 @@
+package ini
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const typedConfig = `name = demo
+debug = yes
+ratio = 0.5
+timeout = 1m30s
+tags = a, b ,c
+
+[server]
+port = 8080
+hosts = x.com,y.com
+bad = eighty
+`
+
+func TestTypedGetters(t *testing.T) {
+	conf := SetConfig(tempConfig(t, typedConfig))
+	if v, err := conf.GetInt("server", "port", 0); v != 8080 || err != nil {
+		t.Errorf("[Error]TestTypedGetters GetInt")
+	}
+	if v, err := conf.GetInt("server", "missing", 7); v != 7 || err != nil {
+		t.Errorf("[Error]TestTypedGetters GetInt default")
+	}
+	if v, err := conf.GetBool("", "debug", false); !v || err != nil {
+		t.Errorf("[Error]TestTypedGetters GetBool")
+	}
+	if v, err := conf.GetFloat("", "ratio", 0); v != 0.5 || err != nil {
+		t.Errorf("[Error]TestTypedGetters GetFloat")
+	}
+	if v, err := conf.GetDuration("", "timeout", 0); v != 90*time.Second || err != nil {
+		t.Errorf("[Error]TestTypedGetters GetDuration")
+	}
+	if v := conf.GetStrings("", "tags", ",", nil); !reflect.DeepEqual(v, []string{"a", "b", "c"}) {
+		t.Errorf("[Error]TestTypedGetters GetStrings %v", v)
+	}
+	if v := conf.GetStrings("", "missing", ",", []string{"d"}); !reflect.DeepEqual(v, []string{"d"}) {
+		t.Errorf("[Error]TestTypedGetters GetStrings default")
+	}
+
+	v, err := conf.GetInt("server", "bad", 80)
+	var ve *ValueError
+	if v != 80 || !errors.As(err, &ve) || ve.Line != 10 || ve.Key != "bad" || !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("[Error]TestTypedGetters ValueError %v", err)
+	}
+}
+
+type serverConfig struct {
+	Port    int      `ini:"port"`
+	Hosts   []string `ini:"hosts"`
+	Ignored string   `ini:"-"`
+}
+
+type appConfig struct {
+	Name    string        `ini:"name"`
+	Debug   bool          `ini:"debug"`
+	Timeout time.Duration `ini:"timeout"`
+	Level   string        `ini:"level"`
+	Server  *serverConfig `ini:"server"`
+	private int
+}
+
+func TestMapTo(t *testing.T) {
+	conf := SetConfig(tempConfig(t, typedConfig))
+	cfg := appConfig{Level: "info"}
+	if err := conf.MapTo(&cfg); err != nil {
+		t.Fatalf("[Error]TestMapTo %v", err)
+	}
+	expected := appConfig{Name: "demo", Debug: true, Timeout: 90 * time.Second, Level: "info",
+		Server: &serverConfig{Port: 8080, Hosts: []string{"x.com", "y.com"}}}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("[Error]TestMapTo %+v", cfg)
+	}
+
+	var bad struct {
+		Server struct {
+			Bad int `ini:"bad"`
+		} `ini:"server"`
+	}
+	var ve *ValueError
+	if err := conf.MapTo(&bad); !errors.As(err, &ve) || ve.Line != 10 {
+		t.Errorf("[Error]TestMapTo ValueError %v", err)
+	}
+	if err := conf.MapTo(cfg); err == nil {
+		t.Errorf("[Error]TestMapTo should need a pointer")
+	}
+}
+
+func TestReflectFrom(t *testing.T) {
+	conf := SetConfig(tempConfig(t, typedConfig))
+	cfg := appConfig{Name: "new", Timeout: time.Second, Server: &serverConfig{Port: 9090, Hosts: []string{"z.com"}}}
+	if err := conf.ReflectFrom(&cfg); err != nil {
+		t.Fatalf("[Error]TestReflectFrom %v", err)
+	}
+	var back appConfig
+	conf.MapTo(&back)
+	if !reflect.DeepEqual(cfg, back) {
+		t.Errorf("[Error]TestReflectFrom %+v", back)
+	}
+}
+
+func TestMapToText(t *testing.T) {
+	var cfg struct {
+		Started time.Time  `ini:"started"`
+		Until   *time.Time `ini:"until"`
+		Never   *time.Time `ini:"never"`
+	}
+	conf, _ := LoadBytes([]byte("started = 2024-01-02T03:04:05Z\nuntil = 2025-01-01T00:00:00Z\n"))
+	if err := conf.MapTo(&cfg); err != nil {
+		t.Fatalf("[Error]TestMapToText %v", err)
+	}
+	if !cfg.Started.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) || cfg.Until == nil || cfg.Until.Year() != 2025 || cfg.Never != nil {
+		t.Errorf("[Error]TestMapToText %+v", cfg)
+	}
+	if s := conf.Sections(); !reflect.DeepEqual(s, []string{""}) {
+		t.Errorf("[Error]TestMapToText sections %q", s)
+	}
+
+	cfg.Started = cfg.Started.Add(time.Hour)
+	conf.ReflectFrom(&cfg)
+	if v, _ := conf.GetValue("", "started"); v != "2024-01-02T04:04:05Z" || conf.HasKey("", "never") {
+		t.Errorf("[Error]TestMapToText ReflectFrom %q", v)
+	}
+	conf.SetValue("", "started", "yesterday")
+	var ve *ValueError
+	if err := conf.MapTo(&cfg); !errors.As(err, &ve) || ve.Key != "started" {
+		t.Errorf("[Error]TestMapToText ValueError %v", err)
+	}
+}
+
+func BenchmarkMapTo(b *testing.B) {
+	conf := SetConfig("init.ini")
+	var cfg struct {
+		AppMode string `ini:"app_mode"`
+		Server  struct {
+			Port int `ini:"http_port"`
+		} `ini:"server"`
+	}
+	for i := 0; i < b.N; i++ {
+		conf.MapTo(&cfg)
+	}
+}
@@ -0,0 +1,310 @@
+package ini
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Rule : 一个key的约束；Key为空时是对section的约束，只检查Required
+type Rule struct {
+	Section  string
+	Key      string
+	Required bool     // key或section必须存在
+	Type     string   // "string"（默认）、"int"、"uint"、"float"、"bool" 或 "duration"
+	Enum     []string // 允许的值，为空时不限制
+	Min      string   // 最小值，按Type解析，string是最小长度；为空时不限制
+	Max      string   // 最大值，同Min
+	Pattern  string   // 值必须匹配的正则表达式
+	Default  string   // key不存在时使用的值，不会被保存到文件
+}
+
+// Schema : 配置的约束
+type Schema []Rule
+
+// ValidationError : Validate发现的所有问题，按Schema的顺序排列
+type ValidationError struct {
+	Errors []*ValueError
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Error]%d invalid values", len(e.Errors))
+	for _, err := range e.Errors {
+		b.WriteString("\n\t")
+		b.WriteString(strings.TrimPrefix(err.Error(), "[Error]"))
+	}
+	return b.String()
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+var (
+	errRequiredKey     = errors.New("required key is missing")
+	errRequiredSection = errors.New("required section is missing")
+	typeOfName         = map[string]reflect.Type{
+		"":         reflect.TypeOf(""),
+		"string":   reflect.TypeOf(""),
+		"int":      reflect.TypeOf(int64(0)),
+		"uint":     reflect.TypeOf(uint64(0)),
+		"float":    reflect.TypeOf(float64(0)),
+		"bool":     reflect.TypeOf(false),
+		"duration": typeDuration,
+	}
+)
+
+// Validate ：检查配置是否满足schema，先给不存在的key设置默认值；返回包含所有问题的 *ValidationError，
+// schema本身有错误时返回其它错误。默认值在Reload和Watcher重新读取后仍然有效，
+// 但是读到的值不会被重新检查，需要时再调用Validate
+func (c *Config) Validate(schema Schema) error {
+	patterns := make([]*regexp.Regexp, len(schema))
+	for i, r := range schema {
+		if _, ok := typeOfName[r.Type]; !ok {
+			return fmt.Errorf("[Error]rule [%s] %s: unknown type %q", r.Section, r.Key, r.Type)
+		}
+		if r.Pattern != "" {
+			re, err := regexp.Compile(r.Pattern)
+			if err != nil {
+				return fmt.Errorf("[Error]rule [%s] %s: %v", r.Section, r.Key, err)
+			}
+			patterns[i] = re
+		}
+	}
+
+	verr := &ValidationError{}
+	for i, r := range schema {
+		sec, key := c.opts.name(r.Section), c.opts.name(r.Key)
+		if key == "" {
+			if r.Required && !c.hasSection(sec) {
+				verr.Errors = append(verr.Errors, &ValueError{File: c.filepath, Section: sec, Err: errRequiredSection})
+			}
+			continue
+		}
+		value, ok := c.lookup(sec, key)
+		if !ok && r.Required {
+			file, line := c.filepath, c.sectionLine(sec)
+			verr.Errors = append(verr.Errors, &ValueError{File: file, Line: line, Section: sec, Key: key, Err: errRequiredKey})
+			continue
+		}
+		if !ok && r.Default == "" {
+			continue
+		}
+		if !ok {
+			c.setDefault(sec, key, r.Default)
+			value = r.Default
+		}
+		if err := r.check(value, patterns[i]); err != nil {
+			file, line := c.position(sec, key)
+			verr.Errors = append(verr.Errors, &ValueError{File: file, Line: line, Section: sec, Key: key, Value: value, Err: err})
+		}
+	}
+	if len(verr.Errors) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// 检查一个值的类型、范围、枚举和正则表达式
+func (r Rule) check(value string, re *regexp.Regexp) error {
+	t := typeOfName[r.Type]
+	v := reflect.New(t).Elem()
+	if err := setValue(v, value); err != nil {
+		return err
+	}
+	if len(r.Enum) > 0 {
+		found := false
+		for _, e := range r.Enum {
+			found = found || e == value
+		}
+		if !found {
+			return fmt.Errorf("must be one of %s", strings.Join(r.Enum, ", "))
+		}
+	}
+	for _, bound := range []struct {
+		s   string
+		min bool
+	}{{r.Min, true}, {r.Max, false}} {
+		if bound.s == "" {
+			continue
+		}
+		b := reflect.New(t).Elem()
+		if t.Kind() == reflect.String {
+			b = reflect.New(typeOfName["int"]).Elem()
+		}
+		if err := setValue(b, bound.s); err != nil {
+			return fmt.Errorf("invalid bound %q: %v", bound.s, err)
+		}
+		n, limit := number(v), number(b)
+		if bound.min && n < limit {
+			return fmt.Errorf("must be at least %s", bound.s)
+		}
+		if !bound.min && n > limit {
+			return fmt.Errorf("must be at most %s", bound.s)
+		}
+	}
+	if re != nil && !re.MatchString(value) {
+		return fmt.Errorf("must match %s", r.Pattern)
+	}
+	return nil
+}
+
+// 用于比较范围的数值，字符串是它的长度
+func number(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return float64(len([]rune(v.String())))
+	}
+	return 0
+}
+
+func (c *Config) hasSection(sec string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.data[sec]
+	return ok
+}
+
+// section所在的行，找不到时为0
+func (c *Config) sectionLine(sec string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, s := range c.document().sections {
+		if s.name == sec {
+			return s.line
+		}
+	}
+	return 0
+}
+
+// 值的来源文件和行，找不到时行为0
+func (c *Config) position(sec, key string) (string, int) {
+	c.mu.RLock()
+	origins := c.origins[keyPath(sec, key)]
+	c.mu.RUnlock()
+	if len(origins) > 0 && origins[0].Line > 0 {
+		return origins[0].Source, origins[0].Line
+	}
+	return c.filepath, c.line(sec, key)
+}
+
+// 设置默认值，只改变内存中的配置，不会被保存；重新读取时没有这个key也使用默认值
+func (c *Config) setDefault(sec, key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.put(sec, key, value, Origin{Source: "default"})
+	// 复制后再修改，merge可能正在读取旧的切片
+	defaults := make([][3]string, 0, len(c.defaults)+1)
+	for _, d := range c.defaults {
+		if d[0] != sec || d[1] != key {
+			defaults = append(defaults, d)
+		}
+	}
+	c.defaults = append(defaults, [3]string{sec, key, value})
+}
+
+// SchemaOf ：从结构体的标签生成Schema，字段和section的对应关系和MapTo相同；
+// 标签 default:"值" 是默认值，validate:"required,oneof=a|b,min=1,max=10,pattern=正则" 是约束，
+// pattern必须放在最后；类型由字段的类型决定
+func SchemaOf(v interface{}) (Schema, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, errNotStruct
+	}
+	return schemaOf("", rv.Type(), true)
+}
+
+func schemaOf(sec string, t reflect.Type, top bool) (Schema, error) {
+	var schema Schema
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, ok := fieldName(f)
+		if !ok {
+			continue
+		}
+		r := Rule{Section: sec, Key: name, Default: f.Tag.Get("default"), Type: typeName(f.Type)}
+		if err := r.parseTag(f.Tag.Get("validate")); err != nil {
+			return nil, fmt.Errorf("[Error]field %s: %v", f.Name, err)
+		}
+		if top && isSection(f.Type) {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if r.Required {
+				schema = append(schema, Rule{Section: name, Required: true})
+			}
+			rules, err := schemaOf(name, ft, false)
+			if err != nil {
+				return nil, err
+			}
+			schema = append(schema, rules...)
+			continue
+		}
+		schema = append(schema, r)
+	}
+	return schema, nil
+}
+
+func typeName(t reflect.Type) string {
+	if t == typeDuration {
+		return "duration"
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "int"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "uint"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.Bool:
+		return "bool"
+	}
+	return "string"
+}
+
+func (r *Rule) parseTag(tag string) error {
+	for tag != "" {
+		item := tag
+		if strings.HasPrefix(tag, "pattern=") {
+			tag = ""
+		} else if i := strings.IndexByte(tag, ','); i >= 0 {
+			item, tag = tag[:i], tag[i+1:]
+		} else {
+			tag = ""
+		}
+		name, arg, _ := strings.Cut(strings.TrimSpace(item), "=")
+		switch name {
+		case "":
+		case "required":
+			r.Required = true
+		case "oneof":
+			r.Enum = strings.Split(arg, "|")
+		case "min":
+			r.Min = arg
+		case "max":
+			r.Max = arg
+		case "pattern":
+			r.Pattern = arg
+		default:
+			return fmt.Errorf("unknown validation %q", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package ini
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+type serverSchema struct {
+	Mode   string `ini:"mode" validate:"required,oneof=dev|prod"`
+	Server struct {
+		Host    string        `ini:"host" default:"localhost" validate:"pattern=^[a-z.]+$"`
+		Port    int           `ini:"port" validate:"required,min=1,max=65535"`
+		Timeout time.Duration `ini:"timeout" default:"30s" validate:"min=1s,max=1m"`
+	} `ini:"server" validate:"required"`
+	DB *struct {
+		Name string `ini:"name" validate:"min=3"`
+	} `ini:"db"`
+}
+
+func TestSchemaOf(t *testing.T) {
+	schema, err := SchemaOf(&serverSchema{})
+	if err != nil || len(schema) != 6 {
+		t.Fatalf("[Error]TestSchemaOf %v %+v", err, schema)
+	}
+	if r := schema[2]; r.Section != "server" || r.Key != "host" || r.Default != "localhost" || r.Pattern != "^[a-z.]+$" {
+		t.Errorf("[Error]TestSchemaOf %+v", r)
+	}
+	if r := schema[3]; r.Type != "int" || !r.Required || r.Min != "1" || r.Max != "65535" {
+		t.Errorf("[Error]TestSchemaOf %+v", r)
+	}
+	if r := schema[1]; r.Section != "server" || r.Key != "" || !r.Required {
+		t.Errorf("[Error]TestSchemaOf section %+v", r)
+	}
+	if _, err := SchemaOf(struct {
+		A int `validate:"positive"`
+	}{}); err == nil {
+		t.Errorf("[Error]TestSchemaOf unknown validation")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	schema, _ := SchemaOf(serverSchema{})
+	conf, _ := LoadBytes([]byte("mode = prod\n[server]\nport = 8080\n"))
+	if err := conf.Validate(schema); err != nil {
+		t.Fatalf("[Error]TestValidate %v", err)
+	}
+	if v, _ := conf.GetDuration("server", "timeout", 0); v != 30*time.Second {
+		t.Errorf("[Error]TestValidate default %v", v)
+	}
+	var s serverSchema
+	conf.MapTo(&s)
+	if s.Server.Host != "localhost" || s.Server.Port != 8080 {
+		t.Errorf("[Error]TestValidate MapTo %+v", s)
+	}
+
+	path := tempConfig(t, "mode = test\n[server]\nhost = Example.com\ntimeout = 2m\n[db]\nname = x\n")
+	conf, _ = Load(path)
+	err := conf.Validate(schema)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || len(verr.Errors) != 5 {
+		t.Fatalf("[Error]TestValidate %v", err)
+	}
+	expected := []struct {
+		key  string
+		line int
+		msg  string
+	}{
+		{"mode", 1, "must be one of dev, prod"},
+		{"host", 3, "must match ^[a-z.]+$"},
+		{"port", 2, "required key is missing"},
+		{"timeout", 4, "must be at most 1m"},
+		{"name", 6, "must be at least 3"},
+	}
+	for i, e := range expected {
+		got := verr.Errors[i]
+		if got.Key != e.key || got.Line != e.line || got.File != path || got.Err.Error() != e.msg {
+			t.Errorf("[Error]TestValidate %d: %v", i, got)
+		}
+	}
+	if !errors.Is(err, errRequiredKey) || !strings.Contains(err.Error(), "5 invalid values") {
+		t.Errorf("[Error]TestValidate %v", err)
+	}
+
+	conf, _ = LoadBytes([]byte("mode = dev\nport = x\n"))
+	err = conf.Validate(Schema{{Section: "server", Required: true}, {Key: "port", Type: "int"}})
+	if !errors.As(err, &verr) || len(verr.Errors) != 2 || verr.Errors[0].Err != errRequiredSection || verr.Errors[1].Line != 2 {
+		t.Errorf("[Error]TestValidate %v", err)
+	}
+	if err := conf.Validate(Schema{{Key: "port", Type: "complex"}}); err == nil {
+		t.Errorf("[Error]TestValidate unknown type")
+	}
+}
+
+func TestValidateReload(t *testing.T) {
+	schema, _ := SchemaOf(serverSchema{})
+	path := tempConfig(t, "mode = prod\n[server]\nport = 8080\n")
+	conf, _ := Load(path)
+	if err := conf.Validate(schema); err != nil {
+		t.Fatalf("[Error]TestValidateReload %v", err)
+	}
+	os.WriteFile(path, []byte("mode = prod\n[server]\nport = 9090\ntimeout = 10s\n"), 0644)
+	if err := conf.Reload(); err != nil {
+		t.Fatalf("[Error]TestValidateReload %v", err)
+	}
+	if v, _ := conf.GetValue("server", "host"); v != "localhost" {
+		t.Errorf("[Error]TestValidateReload host %q", v)
+	}
+	if v, _ := conf.GetDuration("server", "timeout", 0); v != 10*time.Second {
+		t.Errorf("[Error]TestValidateReload timeout %v", v)
+	}
+	if e := conf.Explain("server", "host"); !strings.Contains(e, "(default") {
+		t.Errorf("[Error]TestValidateReload %s", e)
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	schema, _ := SchemaOf(serverSchema{})
+	conf, _ := LoadBytes([]byte("mode = prod\n[server]\nhost = example.com\nport = 8080\n"))
+	for i := 0; i < b.N; i++ {
+		conf.Validate(schema)
+	}
+}
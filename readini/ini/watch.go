@@ -0,0 +1,295 @@
+package ini
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//Diff : 两份配置的区别，key的写法是 "section.key"，默认section中的key没有前缀
+type Diff struct {
+	Added    []string
+	Removed  []string
+	Modified []string
+}
+
+//Empty : 两份配置是否相同
+func (d Diff) Empty() bool {
+	return len(d.Added)+len(d.Removed)+len(d.Modified) == 0
+}
+
+//Match : 是否有一个变化的key匹配paths，"section.*" 匹配section中所有的key，paths为空时匹配任何变化
+func (d Diff) Match(paths ...string) bool {
+	if len(paths) == 0 {
+		return !d.Empty()
+	}
+	for _, keys := range [][]string{d.Added, d.Removed, d.Modified} {
+		for _, key := range keys {
+			for _, path := range paths {
+				if key == path || strings.HasSuffix(path, ".*") && strings.HasPrefix(key, path[:len(path)-1]) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+//ChangeEvent : 一个被监视的文件的变化
+type ChangeEvent struct {
+	Diff
+	File   string
+	Config *Config // Add返回的配置，已经替换为修改后的内容
+	Err    error   // 文件读取或解析失败，此时Config为nil
+}
+
+//Listener : 配置变化的监听者，让开发者自己决定如何处理配置变化
+type Listener interface {
+	OnChange(old, new *Config, diff Diff)
+}
+
+type subscription struct {
+	listener Listener
+	paths    []string
+}
+
+//Watcher : 在后台定时检查一组配置文件，文件内容变化时发送ChangeEvent
+type Watcher struct {
+	Options LoadOptions // 读取文件的选项
+
+	interval time.Duration
+	events   chan ChangeEvent // Events被调用后才发送事件
+	start    sync.Once
+	stopped  bool
+	mu       sync.Mutex
+	files    map[string]*watchedFile
+	subs     []*subscription
+}
+
+type watchedFile struct {
+	modTime int64
+	size    int64
+	conf    *Config
+	loaded  map[string]map[string]string // 上次从文件读到的值，没有保存的SetValue不算文件的变化
+}
+
+//NewWatcher : 创建一个每隔interval检查一次文件的Watcher
+func NewWatcher(interval time.Duration) *Watcher {
+	return &Watcher{interval: interval, files: make(map[string]*watchedFile)}
+}
+
+//Add : 开始监视一个文件，返回它现在的配置
+func (w *Watcher) Add(filename string) (*Config, error) {
+	f := &watchedFile{}
+	f.modTime, f.size = fileStat(filename)
+	conf, err := w.Options.load(filename)
+	if err != nil {
+		return nil, err
+	}
+	f.conf, f.loaded = conf, conf.values()
+	w.mu.Lock()
+	w.files[filename] = f
+	w.mu.Unlock()
+	return conf, nil
+}
+
+//Remove : 不再监视一个文件
+func (w *Watcher) Remove(filename string) {
+	w.mu.Lock()
+	delete(w.files, filename)
+	w.mu.Unlock()
+}
+
+//Events : 文件变化的通知，Watcher停止后被关闭；不调用Events时只通知订阅者。
+//通道中最多有16个没有读取的事件，再发生的事件被丢弃，不影响订阅者和之后的检查
+func (w *Watcher) Events() <-chan ChangeEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.events == nil {
+		w.events = make(chan ChangeEvent, 16)
+		if w.stopped {
+			close(w.events)
+		}
+	}
+	return w.events
+}
+
+//Subscribe : 订阅配置的变化，只有paths中的key变化时才调用listener，paths为空时订阅所有的变化；
+//返回的函数取消订阅
+func (w *Watcher) Subscribe(listener Listener, paths ...string) (cancel func()) {
+	sub := &subscription{listener: listener, paths: paths}
+	w.mu.Lock()
+	w.subs = append(w.subs, sub)
+	w.mu.Unlock()
+	return func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		for i, s := range w.subs {
+			if s == sub {
+				w.subs = append(w.subs[:i:i], w.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+//Start : 在后台开始监视，ctx结束时停止；只有第一次调用有作用
+func (w *Watcher) Start(ctx context.Context) {
+	w.start.Do(func() {
+		go w.run(ctx)
+	})
+}
+
+func (w *Watcher) run(ctx context.Context) {
+	defer func() {
+		w.mu.Lock()
+		w.stopped = true
+		if w.events != nil {
+			close(w.events)
+		}
+		w.mu.Unlock()
+	}()
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.poll()
+		}
+	}
+}
+
+func (w *Watcher) poll() {
+	w.mu.Lock()
+	names := make([]string, 0, len(w.files))
+	for name := range w.files {
+		names = append(names, name)
+	}
+	w.mu.Unlock()
+	sort.Strings(names)
+
+	for _, name := range names {
+		w.mu.Lock()
+		f := w.files[name]
+		w.mu.Unlock()
+		if f == nil {
+			continue
+		}
+		modTime, size := fileStat(name)
+		if modTime == f.modTime && size == f.size {
+			continue
+		}
+		f.modTime, f.size = modTime, size
+
+		e := ChangeEvent{File: name}
+		l, err := f.conf.merge()
+		if err != nil {
+			e.Err = err
+		} else {
+			e.Added, e.Removed, e.Modified = diff(f.loaded, l.data)
+			if e.Empty() {
+				continue // 只是修改时间变了
+			}
+			f.loaded = copyValues(l.data)
+			// 正在读配置的goroutine看到的是替换前或替换后的完整配置
+			old := f.conf.swap(l)
+			e.Config = f.conf
+			w.notify(old, f.conf, e.Diff)
+		}
+
+		w.mu.Lock()
+		events := w.events
+		w.mu.Unlock()
+		if events == nil {
+			continue
+		}
+		select {
+		case events <- e:
+		default:
+			// 没有人读取事件时不能阻塞订阅者的通知
+		}
+	}
+}
+
+func (w *Watcher) notify(old, new *Config, d Diff) {
+	w.mu.Lock()
+	subs := append([]*subscription(nil), w.subs...)
+	w.mu.Unlock()
+	for _, sub := range subs {
+		if d.Match(sub.paths...) {
+			sub.listener.OnChange(old, new, d)
+		}
+	}
+}
+
+// 文件的修改时间和大小，文件不存在时都是0
+func fileStat(filename string) (int64, int64) {
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return 0, 0
+	}
+	return fi.ModTime().UnixNano(), fi.Size()
+}
+
+// 读取一个文件，返回读取或解析的错误
+func (opts LoadOptions) load(filename string) (*Config, error) {
+	conf := &Config{filepath: filename, opts: opts}
+	if err := conf.Reload(); err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+// 所有的值，section -> key -> value
+func (c *Config) values() map[string]map[string]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return copyValues(c.data)
+}
+
+func copyValues(data map[string]map[string]string) map[string]map[string]string {
+	res := make(map[string]map[string]string, len(data))
+	for sec, val := range data {
+		res[sec] = make(map[string]string, len(val))
+		for key, value := range val {
+			res[sec][key] = value
+		}
+	}
+	return res
+}
+
+func keyPath(sec, key string) string {
+	if sec == "" {
+		return key
+	}
+	return sec + "." + key
+}
+
+// 比较两份配置，返回增加、删除和修改的key，按字母顺序排列
+func diff(old, new map[string]map[string]string) (added, removed, modified []string) {
+	for sec, val := range new {
+		for key, value := range val {
+			if oldValue, ok := old[sec][key]; !ok {
+				added = append(added, keyPath(sec, key))
+			} else if oldValue != value {
+				modified = append(modified, keyPath(sec, key))
+			}
+		}
+	}
+	for sec, val := range old {
+		for key := range val {
+			if _, ok := new[sec][key]; !ok {
+				removed = append(removed, keyPath(sec, key))
+			}
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(modified)
+	return
+}
@@ -0,0 +1,175 @@
+package ini
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	old := map[string]map[string]string{"": {"a": "1", "b": "2"}, "s": {"c": "3"}}
+	new := map[string]map[string]string{"": {"a": "1", "b": "3"}, "t": {"d": "4"}}
+	added, removed, modified := diff(old, new)
+	if !reflect.DeepEqual(added, []string{"t.d"}) || !reflect.DeepEqual(removed, []string{"s.c"}) || !reflect.DeepEqual(modified, []string{"b"}) {
+		t.Errorf("[Error]TestDiff %v %v %v", added, removed, modified)
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	path1 := tempConfig(t, "a = 1\n[s]\nb = 2\n")
+	path2 := tempConfig(t, "x = 1\n")
+	w := NewWatcher(10 * time.Millisecond)
+	conf, err := w.Add(path1)
+	if err != nil || conf == nil {
+		t.Fatalf("[Error]TestWatcher %v", err)
+	}
+	w.Add(path2)
+	events := w.Events()
+	ctx, cancel := context.WithCancel(context.Background())
+	w.Start(ctx)
+	w.Start(ctx) // 重复调用没有作用
+
+	os.WriteFile(path1, []byte("a = 2\n[t]\nc = 3\n"), 0644)
+	later := time.Now().Add(time.Hour)
+	os.Chtimes(path1, later, later)
+	select {
+	case e := <-events:
+		if e.File != path1 || e.Err != nil || !reflect.DeepEqual(e.Added, []string{"t.c"}) ||
+			!reflect.DeepEqual(e.Removed, []string{"s.b"}) || !reflect.DeepEqual(e.Modified, []string{"a"}) {
+			t.Errorf("[Error]TestWatcher %+v", e)
+		}
+		if v, _ := conf.GetValue("", "a"); v != "2" || e.Config != conf {
+			t.Errorf("[Error]TestWatcher config not swapped")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("[Error]TestWatcher no event")
+	}
+
+	// 只修改时间不产生事件，解析错误产生事件
+	os.Chtimes(path2, later, later)
+	os.WriteFile(path1, []byte("[bad\n"), 0644)
+	select {
+	case e := <-events:
+		if e.File != path1 || e.Err == nil {
+			t.Errorf("[Error]TestWatcher error event %+v", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("[Error]TestWatcher no error event")
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestWatcherEventsFull(t *testing.T) {
+	path := tempConfig(t, "a = 0\n")
+	w := NewWatcher(10 * time.Millisecond)
+	w.Add(path)
+	events := w.Events() // 不读取
+	l := recordListener{make(chan Diff, 1)}
+	w.Subscribe(l)
+	ctx, cancel := context.WithCancel(context.Background())
+	w.Start(ctx)
+
+	later := time.Now().Add(time.Hour)
+	for i := 1; i <= 20; i++ {
+		os.WriteFile(path, []byte("a = "+strconv.Itoa(i)+"\n"), 0644)
+		later = later.Add(time.Second)
+		os.Chtimes(path, later, later)
+		select {
+		case <-l.diffs:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("[Error]TestWatcherEventsFull not notified of change %d", i)
+		}
+	}
+	cancel()
+	n := 0
+	for range events {
+		n++
+	}
+	if n != cap(events) {
+		t.Errorf("[Error]TestWatcherEventsFull %d events", n)
+	}
+}
+
+type recordListener struct {
+	diffs chan Diff
+}
+
+func (l recordListener) OnChange(old, new *Config, diff Diff) {
+	if old == nil || new == nil {
+		return
+	}
+	l.diffs <- diff
+}
+
+func TestDiffMatch(t *testing.T) {
+	d := Diff{Added: []string{"server.port"}, Modified: []string{"name"}}
+	if !d.Match() || !d.Match("server.port") || !d.Match("server.*") || !d.Match("x", "name") {
+		t.Errorf("[Error]TestDiffMatch")
+	}
+	if d.Match("server") || d.Match("serv.*") || d.Match("paths.*") || (Diff{}).Match() {
+		t.Errorf("[Error]TestDiffMatch should not match")
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	path := tempConfig(t, "a = 1\n[db]\nhost = x\n")
+	w := NewWatcher(10 * time.Millisecond)
+	w.Add(path)
+	all, db, other := recordListener{make(chan Diff, 4)}, recordListener{make(chan Diff, 4)}, recordListener{make(chan Diff, 4)}
+	w.Subscribe(all)
+	w.Subscribe(db, "db.*")
+	cancel := w.Subscribe(other, "a")
+	cancel()
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	w.Start(ctx)
+
+	os.WriteFile(path, []byte("a = 2\n[db]\nhost = y\n"), 0644)
+	later := time.Now().Add(time.Hour)
+	os.Chtimes(path, later, later)
+	select {
+	case d := <-all.diffs:
+		if !reflect.DeepEqual(d.Modified, []string{"a", "db.host"}) {
+			t.Errorf("[Error]TestSubscribe %+v", d)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("[Error]TestSubscribe not notified")
+	}
+	select {
+	case <-db.diffs:
+	case <-time.After(5 * time.Second):
+		t.Errorf("[Error]TestSubscribe db not notified")
+	}
+	if len(other.diffs) != 0 {
+		t.Errorf("[Error]TestSubscribe cancelled listener notified")
+	}
+}
+
+func TestWatcherUnsaved(t *testing.T) {
+	path := tempConfig(t, "a = 1\nb = 1\n")
+	w := NewWatcher(10 * time.Millisecond)
+	conf, _ := w.Add(path)
+	conf.SetValue("", "a", "edited") // 没有保存
+	events := w.Events()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w.Start(ctx)
+
+	os.WriteFile(path, []byte("a = 1\nb = 2\n"), 0644)
+	later := time.Now().Add(time.Hour)
+	os.Chtimes(path, later, later)
+	select {
+	case e := <-events:
+		if len(e.Added)+len(e.Removed) != 0 || !reflect.DeepEqual(e.Modified, []string{"b"}) {
+			t.Errorf("[Error]TestWatcherUnsaved %+v", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("[Error]TestWatcherUnsaved no event")
+	}
+}
@@ -1,26 +1,154 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 
+	"github.com/spf13/pflag"
 	"github.com/yilin0041/service-computing/readini/ini"
 )
 
-func main() {
-	var c *ini.Config
-	c = ini.SetConfig("init.ini")
+type convertArgs struct {
+	from   string
+	to     string
+	out    string
+	watch  bool
+	inFile string
+}
+
+func getArgs(args *convertArgs) {
+	pflag.StringVarP(&(args.from), "from", "f", "", "Define input format: ini, json or env (default by extension)")
+	pflag.StringVarP(&(args.to), "to", "t", "", "Define output format: ini, json or env (default by extension of out_file)")
+	pflag.StringVarP(&(args.out), "out", "o", "", "Define output file (default stdout)")
+	pflag.BoolVarP(&(args.watch), "watch", "w", false, "Watch in_file and print it when changed")
+	pflag.Parse()
+	if argLeft := pflag.Args(); len(argLeft) > 0 {
+		args.inFile = argLeft[0]
+	}
+}
+
+//Usage :the help for user
+func Usage() {
+	fmt.Fprintf(os.Stderr, "USAGE: readini [ -f ini|json|env ] [ -t ini|json|env ] [ -o out_file ] [ in_file ]\n")
+	fmt.Fprintf(os.Stderr, "       readini -w [ in_file ]\n")
+}
+
+// 按扩展名判断格式，没有时返回def
+func formatOf(name, def string) string {
+	switch filepath.Ext(name) {
+	case ".json":
+		return "json"
+	case ".env":
+		return "env"
+	case ".ini", ".conf", ".cfg":
+		return "ini"
+	}
+	return def
+}
+
+func read(args convertArgs) (*ini.Config, error) {
+	format := args.from
+	if format == "" {
+		format = formatOf(args.inFile, "ini")
+	}
+	if format == "ini" && args.inFile != "" {
+		return ini.Load(args.inFile) // 引用的文件相对于in_file
+	}
+	var data []byte
+	var err error
+	if args.inFile == "" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(args.inFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case "ini":
+		return ini.LoadBytes(data)
+	case "json":
+		return ini.FromJSON(data)
+	case "env":
+		return ini.FromDotenv(data)
+	}
+	return nil, fmt.Errorf("[Error]unknown format %q", format)
+}
+
+func write(c *ini.Config, args convertArgs) ([]byte, error) {
+	format := args.to
+	if format == "" {
+		format = formatOf(args.out, "")
+	}
+	switch format {
+	case "ini":
+		var buf bytes.Buffer
+		_, err := c.WriteTo(&buf)
+		return buf.Bytes(), err
+	case "json":
+		data, err := c.ToJSON()
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, data, "", "  "); err != nil {
+			return nil, err
+		}
+		buf.WriteByte('\n')
+		return buf.Bytes(), nil
+	case "env":
+		return c.ToDotenv()
+	case "":
+		return nil, fmt.Errorf("[Error]the output format can't be empty")
+	}
+	return nil, fmt.Errorf("[Error]unknown format %q", format)
+}
+
+// 每次文件变化时打印新的配置
+func watch(filename string) {
+	if filename == "" {
+		filename = "init.ini"
+	}
 	MyListen := func(string) {
-		fmt.Printf("My listen test!\n")
+		fmt.Printf("%s changed!\n", filename)
+	}
+	for {
+		c, err := ini.Watch(filename, MyListen)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error in watch: %v\n", err)
+			continue
+		}
+		c.WriteTo(os.Stdout)
 	}
-	c, err := ini.Watch("init.ini", MyListen)
-	//人工在server中加入test=123
+}
+
+func main() {
+	var args convertArgs
+	getArgs(&args)
+	if args.watch {
+		watch(args.inFile)
+		return
+	}
+	c, err := read(args)
 	if err != nil {
-		fmt.Printf("error in watch!\n")
-	}
-	v1, _ := c.GetValue("", "app_mode")
-	v2, _ := c.GetValue("paths", "data")
-	v3, _ := c.GetValue("server", "test")
-	fmt.Printf("%s\n", v1)
-	fmt.Printf("%s\n", v2)
-	fmt.Printf("%s\n", v3)
+		fmt.Fprintln(os.Stderr, err)
+		Usage()
+		os.Exit(1)
+	}
+	data, err := write(c, args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		Usage()
+		os.Exit(2)
+	}
+	if args.out == "" {
+		os.Stdout.Write(data)
+	} else if err := os.WriteFile(args.out, data, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(3)
+	}
 }
@@ -0,0 +1,136 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SetBatch sends the items of the Observable to the next stage in batches of up to size items, so a
+// batch costs one channel operation. A batch is sent when it is full, latency after its first item,
+// or when the flow ends; latency 0 means no time bound. Batching is transparent to operators and
+// observers, and only takes effect when the next stage is a transform, a filter or the observer.
+func (o *Observable) SetBatch(size int, latency time.Duration) *Observable {
+	o.batchSize = size
+	o.batchLatency = latency
+	return o
+}
+
+// items sent in one channel operation
+type itemBatch []interface{}
+
+// flowReader receives items from a flow, unpacking batches
+type flowReader struct {
+	in    chan interface{}
+	batch itemBatch
+}
+
+func newFlowReader(in chan interface{}) *flowReader {
+	return &flowReader{in: in}
+}
+
+func (r *flowReader) next() (x interface{}, ok bool) {
+	if len(r.batch) == 0 {
+		if x, ok = <-r.in; !ok {
+			return
+		}
+		b, isBatch := x.(itemBatch)
+		if !isBatch {
+			return
+		}
+		r.batch = b
+	}
+	x, r.batch = r.batch[0], r.batch[1:]
+	return x, true
+}
+
+// report whether the operator of o reads its input by a flowReader
+func unbatches(o *Observable) bool {
+	switch o.operator.(type) {
+	case transOperater, filteringOperator:
+		return true
+	}
+	return false
+}
+
+// collects the items sent to a batched flow in a connection
+type batcher struct {
+	mu      sync.Mutex
+	ctx     context.Context
+	out     chan interface{}
+	size    int
+	latency time.Duration
+	items   itemBatch
+	timer   *time.Timer
+	closed  bool
+}
+
+type batcherKey struct{}
+
+// attach a batcher of the flow of o to ctx
+func (o *Observable) newBatcher(ctx context.Context) context.Context {
+	b := &batcher{out: o.outflow, size: o.batchSize, latency: o.batchLatency}
+	b.ctx = context.WithValue(ctx, batcherKey{}, b)
+	return b.ctx
+}
+
+// the batcher of out, or nil if out is not batched
+func batcherOf(ctx context.Context, out chan interface{}) *batcher {
+	if b, ok := ctx.Value(batcherKey{}).(*batcher); ok && b.out == out {
+		return b
+	}
+	return nil
+}
+
+func (b *batcher) add(ctx context.Context, item interface{}) (end bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.items = append(b.items, item)
+	if len(b.items) >= b.size {
+		return b.flush(ctx)
+	}
+	if len(b.items) == 1 && b.latency > 0 {
+		b.timer = time.AfterFunc(b.latency, func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if !b.closed {
+				b.flush(b.ctx)
+			}
+		})
+	}
+	return ctx.Err() != nil
+}
+
+// send the pending items, b.mu must be held
+func (b *batcher) flush(ctx context.Context) (end bool) {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if len(b.items) == 0 {
+		return false
+	}
+	var x interface{} = b.items
+	if len(b.items) == 1 {
+		x = b.items[0]
+	}
+	b.items = nil
+	select {
+	case b.out <- x:
+	case <-ctx.Done():
+		end = true
+	}
+	return
+}
+
+// send the pending items before the flow is closed
+func (b *batcher) close(ctx context.Context) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.flush(ctx)
+	b.closed = true
+}
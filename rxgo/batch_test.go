@@ -0,0 +1,93 @@
+package rxgo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yilin0041/service-computing/rxgo"
+)
+
+func TestSetBatch(t *testing.T) {
+	expected := []int{}
+	for i := 0; i < 100; i++ {
+		if i%3 != 0 {
+			expected = append(expected, i+1)
+		}
+	}
+
+	res := []int{}
+	errs := 0
+	rxgo.Range(0, 100).SetBatch(16, time.Millisecond).Map(func(x int) interface{} {
+		if x%3 == 0 {
+			return errors.New("any")
+		}
+		return x + 1
+	}).SetBatch(7, 0).Filter(func(x int) bool {
+		return true
+	}).SetBatch(5, 0).Subscribe(rxgo.ObserverMonitor{
+		Next: func(x interface{}) {
+			res = append(res, x.(int))
+		},
+		Error: func(e error) {
+			errs++
+		},
+	})
+	assert.Equal(t, expected, res, "SetBatch Test Error!")
+	assert.Equal(t, 34, errs, "SetBatch Test Error!")
+}
+
+func TestSetBatchLatency(t *testing.T) {
+	received := make(chan int)
+	done := make(chan bool)
+	go func() {
+		defer close(done)
+		rxgo.Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+			for i := 0; i < 3; i++ {
+				send(i)
+				// the next item is sent only after this one arrives
+				select {
+				case <-received:
+				case <-time.After(time.Second):
+					return
+				}
+			}
+		}).SetBatch(100, time.Millisecond).Subscribe(func(x int) {
+			received <- x
+		})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Errorf("Batch not flushed by latency")
+	}
+}
+
+func TestSetBatchThreading(t *testing.T) {
+	sum := 0
+	rxgo.Range(0, 100).Map(func(x int) int {
+		return x
+	}).SubscribeOn(rxgo.ThreadingIO).SetBatch(8, time.Millisecond).Subscribe(func(x int) {
+		sum += x
+	})
+	assert.Equal(t, 4950, sum, "SetBatch Test Error!")
+}
+
+func benchmarkTransport(b *testing.B, batch int) {
+	for i := 0; i < b.N; i++ {
+		rxgo.Range(0, 10000).SetBatch(batch, time.Millisecond).Map(func(x int) int {
+			return x + 1
+		}).SetBatch(batch, time.Millisecond).Subscribe(func(x int) {})
+	}
+}
+
+func BenchmarkTransport(b *testing.B) {
+	benchmarkTransport(b, 1)
+}
+
+func BenchmarkTransportBatched(b *testing.B) {
+	benchmarkTransport(b, 64)
+}
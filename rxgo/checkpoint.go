@@ -0,0 +1,265 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Checkpoint operator error, the pipeline has a stage that can not pass checkpoints
+var ErrCheckpointUnsupported = errors.New("Checkpoint is only supported by transform and filtering operators read by Subscribe")
+
+// Checkpoint operator error, the source can not emit the same items again and has no seek function
+var ErrCheckpointSource = errors.New("Checkpoint needs a replayable source or a source with SetSeek")
+
+// Checkpoint is the progress of a pipeline. The items before Position emitted by the source
+// have been consumed by the subscriber, and States holds the state of stateful stages at that moment,
+// keyed by the index of the stage in the chain.
+type Checkpoint struct {
+	Position int
+	States   map[int][]byte
+}
+
+// CheckpointStore keeps the latest Checkpoint of pipelines by id
+type CheckpointStore interface {
+	Load(id string) (*Checkpoint, error) // returns nil Checkpoint if id has none
+	Save(id string, cp *Checkpoint) error
+}
+
+// FileCheckpointStore saves each Checkpoint to a gob file in Dir, replacing the file atomically
+type FileCheckpointStore struct {
+	Dir string
+}
+
+var _ CheckpointStore = FileCheckpointStore{}
+
+func NewFileCheckpointStore(dir string) FileCheckpointStore {
+	return FileCheckpointStore{Dir: dir}
+}
+
+func (s FileCheckpointStore) path(id string) string {
+	return filepath.Join(s.Dir, id+".checkpoint")
+}
+
+func (s FileCheckpointStore) Load(id string) (*Checkpoint, error) {
+	data, err := ioutil.ReadFile(s.path(id))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cp := new(Checkpoint)
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(cp); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+func (s FileCheckpointStore) Save(id string, cp *Checkpoint) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(cp); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(s.Dir, id+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(buf.Bytes()); err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), s.path(id))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// shared by the source and the checkpoint stage of a pipeline
+type checkpointConfig struct {
+	store CheckpointStore
+	id    string
+	every int
+	// set when connected
+	restored *Checkpoint
+	loadErr  error
+	emitted  int
+	observed bool // barriers reach Subscribe, that saves them
+}
+
+// a barrier flows after the items before position, stages add their state to it on the way
+type checkpointBarrier struct {
+	position int
+	states   map[int][]byte
+	err      error
+	save     *checkpointConfig // set by the Checkpoint stage
+}
+
+// Checkpoint saves the progress of the pipeline to store under id every `every` items emitted by its source,
+// and when the source completes. A checkpoint is saved by Subscribe once the subscriber has consumed
+// every item before it. When subscribed again, the source skips the items consumed before the saved
+// checkpoint and stateful stages such as Distinct restore their state. Types of items kept in state must be
+// registered by gob.Register unless they are basic types.
+//
+// Just, Range, From(slice), Empty and Throw emit the same items after a restart. Other sources, such as
+// From(chan) and Start, must call SetSeek before Checkpoint to continue from the saved position.
+// Only transform and filtering operators may be in the pipeline, or ErrCheckpointUnsupported flows
+// to the subscriber and nothing is saved.
+func (parent *Observable) Checkpoint(store CheckpointStore, id string, every int) (o *Observable) {
+	if every <= 0 {
+		panic(ErrFuncFlip)
+	}
+	if !parent.root.replayable && parent.root.seek == nil {
+		panic(ErrCheckpointSource)
+	}
+	for po := parent; po != nil; po = po.pred {
+		if _, ok := po.operator.(combiningOperator); ok {
+			panic(ErrCheckpointUnsupported)
+		}
+	}
+
+	o = parent.newTransformObservable("checkpoint")
+	o.checkpoint = &checkpointConfig{store: store, id: id, every: every}
+	o.root.checkpoint = o.checkpoint
+	o.operator = passOperater
+	return o
+}
+
+// SetSeek sets the function that moves a source, which can not emit the same items again, to the position
+// of the saved checkpoint when a checkpointed pipeline is connected. The source then emits the items
+// from there and nothing is skipped. It must be called on the source before Checkpoint.
+func (o *Observable) SetSeek(seek func(position int) error) *Observable {
+	o.root.seek = seek
+	return o
+}
+
+// load the checkpoint before the pipeline from stages[0] is connected
+func (c *checkpointConfig) load(stages []*Observable, observed bool) {
+	c.restored, c.loadErr = c.store.Load(c.id)
+	if c.restored == nil {
+		c.restored = &Checkpoint{States: map[int][]byte{}}
+	}
+	c.emitted = 0
+	if seek := stages[0].seek; seek != nil {
+		c.emitted = c.restored.Position
+		if err := seek(c.restored.Position); c.loadErr == nil {
+			c.loadErr = err
+		}
+	}
+
+	// stages after the Checkpoint stage must pass barriers on to Subscribe
+	c.observed = observed
+	after := false
+	for _, po := range stages {
+		if after && !unbatches(po) {
+			c.observed = false
+		}
+		after = after || po.checkpoint == c && po.root != po
+	}
+	if !c.observed && c.loadErr == nil {
+		c.loadErr = ErrCheckpointUnsupported
+	}
+}
+
+// index of o in its chain
+func (o *Observable) index() (i int) {
+	for po := o.pred; po != nil; po = po.pred {
+		i++
+	}
+	return
+}
+
+// restored state of o, nil if there is none
+func (o *Observable) restoredState() []byte {
+	c := o.root.checkpoint
+	if c == nil || c.restored == nil {
+		return nil
+	}
+	return c.restored.States[o.index()]
+}
+
+// the source sends items through here when checkpointing
+func (o *Observable) sendCheckpointed(ctx context.Context, item interface{}, out chan interface{}) (end bool) {
+	c := o.checkpoint
+	c.emitted++
+	if c.emitted <= c.restored.Position {
+		return false // processed before the restart
+	}
+	if end = o.send(ctx, item, out); end {
+		return
+	}
+	if c.emitted%c.every == 0 {
+		end = o.send(ctx, &checkpointBarrier{position: c.emitted, states: map[int][]byte{}}, out)
+	}
+	return
+}
+
+// the last checkpoint when the source completes
+func (o *Observable) sendFinalBarrier(ctx context.Context, out chan interface{}) {
+	if c := o.checkpoint; c != nil && o.root == o && ctx.Err() == nil {
+		o.send(ctx, &checkpointBarrier{position: c.emitted, states: map[int][]byte{}}, out)
+	}
+}
+
+// pass a barrier to the next stage, the checkpoint stage marks it to be saved by Subscribe
+func (o *Observable) passBarrier(ctx context.Context, b *checkpointBarrier, out chan interface{}) (end bool) {
+	c := o.checkpoint
+	if c == nil || o.root == o {
+		return o.send(ctx, b, out)
+	}
+
+	err := b.err
+	if c.loadErr != nil {
+		err, c.loadErr = c.loadErr, nil
+	}
+	if err != nil {
+		return o.sendToFlow(ctx, err, out)
+	}
+	if !c.observed {
+		return false
+	}
+	b.save = c
+	return o.send(ctx, b, out)
+}
+
+// called by Subscribe after the subscriber has consumed the items before the barrier
+func (b *checkpointBarrier) commit() error {
+	c := b.save
+	if c == nil {
+		return nil
+	}
+	return c.store.Save(c.id, &Checkpoint{Position: b.position, States: b.states})
+}
+
+// state of a filtering stage in a checkpoint
+type filteringState struct {
+	Seen    []interface{}
+	SeenAt  []time.Time
+	LastKey interface{}
+	HasLast bool
+	Out     []interface{}
+}
+
+func encodeState(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+func decodeState(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
@@ -0,0 +1,153 @@
+package rxgo_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yilin0041/service-computing/rxgo"
+)
+
+// crash stops the flow at item stop, like a process killed while handling it
+func crash(stop int) func(x int) int {
+	return func(x int) int {
+		if x == stop {
+			panic(rxgo.ErrEoFlow)
+		}
+		return x
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rxgo")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	store := rxgo.NewFileCheckpointStore(dir)
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	res := []int{}
+	rxgo.From(items).Map(crash(5)).Checkpoint(store, "job", 2).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+	assert.Equal(t, []int{1, 2, 3, 4}, res, "Checkpoint Test Error!")
+	cp, err := store.Load("job")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, cp.Position, "Checkpoint Test Error!")
+
+	res = []int{}
+	rxgo.From(items).Map(crash(0)).Checkpoint(store, "job", 2).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+	assert.Equal(t, []int{5, 6, 7, 8, 9, 10}, res, "Checkpoint Resume Test Error!")
+
+	res = []int{}
+	rxgo.From(items).Checkpoint(store, "job", 2).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+	assert.Equal(t, []int{}, res, "Checkpoint Completed Test Error!")
+}
+
+func TestCheckpointDistinct(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rxgo")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	store := rxgo.NewFileCheckpointStore(dir)
+
+	res := []int{}
+	rxgo.Just(1, 2, 1, 3, 2, 4).Distinct().Map(crash(3)).Checkpoint(store, "distinct", 2).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+	assert.Equal(t, []int{1, 2}, res, "Checkpoint Distinct Test Error!")
+
+	res = []int{}
+	rxgo.Just(1, 2, 1, 3, 2, 4).Distinct().Map(crash(0)).Checkpoint(store, "distinct", 2).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+	assert.Equal(t, []int{3, 4}, res, "Checkpoint Distinct Resume Test Error!")
+}
+
+func TestCheckpointConsumed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rxgo")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	store := rxgo.NewFileCheckpointStore(dir)
+
+	// the subscriber hangs on item 3 while the pipeline runs ahead
+	reached, release, done := make(chan bool), make(chan bool), make(chan bool)
+	go func() {
+		rxgo.Range(1, 11).Checkpoint(store, "job", 1).Subscribe(func(x int) {
+			if x == 3 {
+				reached <- true
+				<-release
+			}
+		})
+		close(done)
+	}()
+	<-reached
+	time.Sleep(10 * time.Millisecond)
+	cp, err := store.Load("job")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, cp.Position, "Checkpoint Consumed Test Error!")
+
+	close(release)
+	<-done
+	cp, err = store.Load("job")
+	assert.NoError(t, err)
+	assert.Equal(t, 10, cp.Position, "Checkpoint Consumed Test Error!")
+}
+
+func TestCheckpointSeek(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rxgo")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	store := rxgo.NewFileCheckpointStore(dir)
+
+	// produce items after position up to n, like a log read from an offset
+	run := func(n int) []int {
+		ch := make(chan int)
+		res := []int{}
+		rxgo.From(ch).SetSeek(func(position int) error {
+			go func() {
+				for i := position + 1; i <= n; i++ {
+					ch <- i
+				}
+				close(ch)
+			}()
+			return nil
+		}).Checkpoint(store, "chan", 2).Subscribe(func(x int) {
+			res = append(res, x)
+		})
+		return res
+	}
+	assert.Equal(t, []int{1, 2, 3, 4}, run(4), "Checkpoint Seek Test Error!")
+	assert.Equal(t, []int{5, 6}, run(6), "Checkpoint Seek Resume Test Error!")
+	cp, err := store.Load("chan")
+	assert.NoError(t, err)
+	assert.Equal(t, 6, cp.Position, "Checkpoint Seek Test Error!")
+}
+
+func TestCheckpointUnsupported(t *testing.T) {
+	assert.Panics(t, func() {
+		rxgo.Just(1).StartWith(0).Checkpoint(rxgo.NewFileCheckpointStore(""), "job", 1)
+	}, "Checkpoint Unsupported Test Error!")
+	assert.PanicsWithValue(t, rxgo.ErrCheckpointSource, func() {
+		rxgo.From(make(chan int)).Checkpoint(rxgo.NewFileCheckpointStore(""), "job", 1)
+	}, "Checkpoint Source Test Error!")
+
+	dir, err := ioutil.TempDir("", "rxgo")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	store := rxgo.NewFileCheckpointStore(dir)
+	var errs []error
+	rxgo.Just(1, 2).Checkpoint(store, "job", 1).StartWith(0).Subscribe(rxgo.ObserverMonitor{
+		Error: func(e error) {
+			errs = append(errs, e)
+		},
+	})
+	assert.Equal(t, []error{rxgo.ErrCheckpointUnsupported}, errs, "Checkpoint Unsupported Test Error!")
+	cp, err := store.Load("job")
+	assert.NoError(t, err)
+	assert.Nil(t, cp, "Checkpoint Unsupported Test Error!")
+}
@@ -0,0 +1,101 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the time source of time-based operators, tests may replace it with a VirtualClock
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// SystemClock is the default Clock of Observables
+var SystemClock Clock = systemClock{}
+
+// SetClock sets the time source of a time-based operator
+func (o *Observable) SetClock(c Clock) *Observable {
+	o.clock = c
+	return o
+}
+
+func (o *Observable) now() time.Time {
+	if o.clock == nil {
+		return SystemClock.Now()
+	}
+	return o.clock.Now()
+}
+
+func (o *Observable) after(d time.Duration) <-chan time.Time {
+	if o.clock == nil {
+		return SystemClock.After(d)
+	}
+	return o.clock.After(d)
+}
+
+// VirtualClock is a Clock that only moves forward by Advance
+type VirtualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []virtualTimer
+}
+
+type virtualTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+var _ Clock = &VirtualClock{}
+
+func NewVirtualClock(now time.Time) *VirtualClock {
+	return &VirtualClock{now: now}
+}
+
+func (c *VirtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *VirtualClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.timers = append(c.timers, virtualTimer{c.now.Add(d), ch})
+	sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].at.Before(c.timers[j].at) })
+	return ch
+}
+
+// Advance moves the clock forward by d and fires the timers that are due
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	i := 0
+	for ; i < len(c.timers) && !c.timers[i].at.After(c.now); i++ {
+		c.timers[i].ch <- c.timers[i].at
+	}
+	c.timers = c.timers[i:]
+}
+
+// Waiters returns the number of timers not fired yet, so a test knows when the flow is waiting on the clock
+func (c *VirtualClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
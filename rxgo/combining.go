@@ -0,0 +1,414 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+var typeItems = reflect.TypeOf([]interface{}{})
+
+// combining node implementation of streamOperator
+type combiningOperator struct {
+	opFunc func(ctx context.Context, o *Observable, ins []chan interface{}, out chan interface{})
+}
+
+func (cop combiningOperator) op(ctx context.Context, o *Observable) {
+	// the first input is the parent, the others are the last Observables of the combined chains
+	ins := []chan interface{}{o.pred.outflow}
+	for _, other := range o.others {
+		po := other
+		for ; po.next != nil; po = po.next {
+		}
+		ins = append(ins, po.outflow)
+	}
+	out := o.outflow
+
+	go func() {
+		cop.opFunc(ctx, o, ins, out)
+		o.closeFlow(ctx, out)
+	}()
+}
+
+func (parent *Observable) newCombiningObservable(name string, others ...*Observable) (o *Observable) {
+	//new Observable
+	o = newObservable()
+	o.Name = name
+
+	//chain Observables
+	parent.next = o
+	o.pred = parent
+	o.root = parent.root
+	o.others = others
+
+	//set options
+	o.buf_len = BufferLen
+	return o
+}
+
+// a combining operator stops reading an input early, but the input chain must not block on it
+func drainFlow(in chan interface{}) {
+	if in != nil {
+		go func() {
+			for range in {
+			}
+		}()
+	}
+}
+
+// call combiner `func(x, y anytype) anytype` and send its result
+func (o *Observable) sendCombined(ctx context.Context, x, y interface{}, out chan interface{}) (end bool) {
+	fv := reflect.ValueOf(o.flip)
+	params := []reflect.Value{reflect.ValueOf(x), reflect.ValueOf(y)}
+	if o.flip_sup_ctx {
+		params = append([]reflect.Value{reflect.ValueOf(ctx)}, params...)
+	}
+	rs, skip, stop, e := userFuncCall(fv, params)
+	if stop {
+		return true
+	}
+	if skip {
+		return false
+	}
+	var item interface{}
+	if e != nil {
+		item = e
+	} else {
+		item = rs[0].Interface()
+	}
+	return o.sendToFlow(ctx, item, out)
+}
+
+func checkCombiner(f interface{}, inType []reflect.Type) (fv reflect.Value, ctx_sup bool) {
+	fv = reflect.ValueOf(f)
+	outType := []reflect.Type{typeAny}
+	b, ctx_sup := checkFuncUpcast(fv, inType, outType, true)
+	if !b {
+		panic(ErrFuncFlip)
+	}
+	return
+}
+
+// WithLatestFrom combines each item from the Observable with the latest item from other by the function
+// `func(x, y anytype) anytype`. Items are dropped until other has emitted at least once.
+func (parent *Observable) WithLatestFrom(other *Observable, combiner interface{}) (o *Observable) {
+	fv, ctx_sup := checkCombiner(combiner, []reflect.Type{typeAny, typeAny})
+
+	o = parent.newCombiningObservable("withLatestFrom", other)
+	o.flip_sup_ctx = ctx_sup
+	o.flip = fv.Interface()
+	o.operator = withLatestFromOperator
+	return o
+}
+
+var withLatestFromOperator = combiningOperator{func(ctx context.Context, o *Observable, ins []chan interface{}, out chan interface{}) {
+	in, latestIn := ins[0], ins[1]
+	var latest interface{}
+	hasLatest := false
+
+	for in != nil {
+		select {
+		case x, ok := <-in:
+			if !ok {
+				in = nil
+				break
+			}
+			if e, ok := x.(error); ok {
+				if o.sendToFlow(ctx, e, out) {
+					in = nil
+				}
+				break
+			}
+			if hasLatest && o.sendCombined(ctx, x, latest, out) {
+				in = nil
+			}
+		case y, ok := <-latestIn:
+			if !ok {
+				latestIn = nil
+				break
+			}
+			if e, ok := y.(error); ok {
+				if o.sendToFlow(ctx, e, out) {
+					in = nil
+				}
+				break
+			}
+			latest, hasLatest = y, true
+		case <-ctx.Done():
+			in = nil
+		}
+	}
+	drainFlow(ins[0])
+	drainFlow(latestIn)
+}}
+
+// an item that still accepts items from the opposite stream until its deadline
+type windowItem struct {
+	item     interface{}
+	deadline time.Time
+	group    []interface{}
+}
+
+// remove the items whose window is closed, in arrival order
+func expireWindow(items []*windowItem, now time.Time) (open, closed []*windowItem) {
+	i := 0
+	for ; i < len(items) && items[i].deadline.Before(now); i++ {
+	}
+	return items[i:], items[:i]
+}
+
+// Join combines items emitted by the Observable and other by the function `func(left, right anytype) anytype`
+// whenever a left item arrives while a right one is open, or the reverse. An item is open for leftWindow
+// (or rightWindow) after it arrives.
+func (parent *Observable) Join(other *Observable, leftWindow, rightWindow time.Duration, resultSelector interface{}) (o *Observable) {
+	fv, ctx_sup := checkCombiner(resultSelector, []reflect.Type{typeAny, typeAny})
+
+	o = parent.newCombiningObservable("join", other)
+	o.leftWindow, o.rightWindow = leftWindow, rightWindow
+	o.flip_sup_ctx = ctx_sup
+	o.flip = fv.Interface()
+	o.operator = joinOperator
+	return o
+}
+
+var joinOperator = combiningOperator{func(ctx context.Context, o *Observable, ins []chan interface{}, out chan interface{}) {
+	left, right := ins[0], ins[1]
+	var lefts, rights []*windowItem
+
+	for left != nil || right != nil {
+		var x interface{}
+		var ok, isLeft bool
+		select {
+		case x, ok = <-left:
+			isLeft = true
+		case x, ok = <-right:
+		case <-ctx.Done():
+			drainFlow(left)
+			drainFlow(right)
+			return
+		}
+
+		now := o.now()
+		lefts, _ = expireWindow(lefts, now)
+		rights, _ = expireWindow(rights, now)
+		switch {
+		case !ok && isLeft:
+			left = nil
+		case !ok:
+			right = nil
+		case isError(x):
+			if o.sendToFlow(ctx, x, out) {
+				drainFlow(left)
+				drainFlow(right)
+				return
+			}
+		case isLeft:
+			lefts = append(lefts, &windowItem{item: x, deadline: now.Add(o.leftWindow)})
+			for _, r := range rights {
+				if o.sendCombined(ctx, x, r.item, out) {
+					drainFlow(left)
+					drainFlow(right)
+					return
+				}
+			}
+		default:
+			rights = append(rights, &windowItem{item: x, deadline: now.Add(o.rightWindow)})
+			for _, l := range lefts {
+				if o.sendCombined(ctx, l.item, x, out) {
+					drainFlow(left)
+					drainFlow(right)
+					return
+				}
+			}
+		}
+
+		// nothing can be joined once a completed stream has no open item
+		if (left == nil && len(lefts) == 0) || (right == nil && len(rights) == 0) {
+			drainFlow(left)
+			drainFlow(right)
+			return
+		}
+	}
+}}
+
+// GroupJoin is like Join, but emits once per left item when its window closes, calling
+// `func(left anytype, rights []interface{}) anytype` with all right items that overlapped it.
+func (parent *Observable) GroupJoin(other *Observable, leftWindow, rightWindow time.Duration, resultSelector interface{}) (o *Observable) {
+	fv, ctx_sup := checkCombiner(resultSelector, []reflect.Type{typeAny, typeItems})
+
+	o = parent.newCombiningObservable("groupJoin", other)
+	o.leftWindow, o.rightWindow = leftWindow, rightWindow
+	o.flip_sup_ctx = ctx_sup
+	o.flip = fv.Interface()
+	o.operator = groupJoinOperator
+	return o
+}
+
+var groupJoinOperator = combiningOperator{func(ctx context.Context, o *Observable, ins []chan interface{}, out chan interface{}) {
+	left, right := ins[0], ins[1]
+	var lefts, rights []*windowItem
+
+	emit := func(groups []*windowItem) (end bool) {
+		for _, l := range groups {
+			if l.group == nil {
+				l.group = []interface{}{}
+			}
+			if o.sendCombined(ctx, l.item, l.group, out) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for left != nil || len(lefts) > 0 {
+		// no more right items can arrive, so every open group is final
+		if right == nil && left == nil {
+			emit(lefts)
+			return
+		}
+
+		var expired <-chan time.Time
+		if len(lefts) > 0 {
+			expired = o.after(lefts[0].deadline.Sub(o.now()))
+		}
+
+		var x interface{}
+		var ok, isLeft, isTick bool
+		select {
+		case x, ok = <-left:
+			isLeft = true
+		case x, ok = <-right:
+		case <-expired:
+			isTick = true
+		case <-ctx.Done():
+			drainFlow(left)
+			drainFlow(right)
+			return
+		}
+
+		now := o.now()
+		var closed []*windowItem
+		lefts, closed = expireWindow(lefts, now)
+		rights, _ = expireWindow(rights, now)
+		if emit(closed) {
+			drainFlow(left)
+			drainFlow(right)
+			return
+		}
+		switch {
+		case isTick:
+		case !ok && isLeft:
+			left = nil
+		case !ok:
+			right = nil
+		case isError(x):
+			if o.sendToFlow(ctx, x, out) {
+				drainFlow(left)
+				drainFlow(right)
+				return
+			}
+		case isLeft:
+			l := &windowItem{item: x, deadline: now.Add(o.leftWindow)}
+			for _, r := range rights {
+				l.group = append(l.group, r.item)
+			}
+			lefts = append(lefts, l)
+		default:
+			rights = append(rights, &windowItem{item: x, deadline: now.Add(o.rightWindow)})
+			for _, l := range lefts {
+				l.group = append(l.group, x)
+			}
+		}
+	}
+	drainFlow(right)
+}}
+
+func isError(x interface{}) bool {
+	_, ok := x.(error)
+	return ok
+}
+
+// StartWith emits the given items before it begins to emit items from the Observable.
+func (parent *Observable) StartWith(items ...interface{}) (o *Observable) {
+	o = parent.newCombiningObservable("startWith")
+	o.flip = items
+	o.operator = startWithOperator
+	return o
+}
+
+var startWithOperator = combiningOperator{func(ctx context.Context, o *Observable, ins []chan interface{}, out chan interface{}) {
+	for _, item := range o.flip.([]interface{}) {
+		if o.sendToFlow(ctx, item, out) {
+			drainFlow(ins[0])
+			return
+		}
+	}
+	for x := range ins[0] {
+		if o.sendToFlow(ctx, x, out) {
+			drainFlow(ins[0])
+			return
+		}
+	}
+}}
+
+// Repeat re-subscribes the Observable until its items are emitted n times.
+// A negative n repeats them until the observer unsubscribes.
+func (parent *Observable) Repeat(n int) (o *Observable) {
+	o = parent.newCombiningObservable("repeat")
+	o.repeat = n
+	o.operator = repeatOperator
+	return o
+}
+
+// RepeatWhen re-subscribes the Observable once for every item emitted by notifier,
+// and completes when notifier completes.
+func (parent *Observable) RepeatWhen(notifier *Observable) (o *Observable) {
+	o = parent.newCombiningObservable("repeatWhen", notifier)
+	o.repeat = -1
+	o.operator = repeatOperator
+	return o
+}
+
+var repeatOperator = combiningOperator{func(ctx context.Context, o *Observable, ins []chan interface{}, out chan interface{}) {
+	in := ins[0]
+	var notifier chan interface{}
+	if len(ins) > 1 {
+		notifier = ins[1]
+		defer func() { drainFlow(notifier) }()
+	}
+
+	for pass := 0; o.repeat < 0 || pass < o.repeat; pass++ {
+		if pass > 0 {
+			if notifier != nil {
+				select {
+				case _, ok := <-notifier:
+					if !ok {
+						notifier = nil
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			// the upstream chain has completed, so it is safe to connect it again
+			o.pred.reconnect(ctx)
+			in = o.pred.outflow
+		}
+		for x := range in {
+			if o.sendToFlow(ctx, x, out) {
+				drainFlow(in)
+				return
+			}
+		}
+	}
+	if o.repeat == 0 {
+		drainFlow(in)
+	}
+}}
@@ -0,0 +1,90 @@
+package rxgo_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yilin0041/service-computing/rxgo"
+)
+
+func TestWithLatestFrom(t *testing.T) {
+	res := []string{}
+	latest := rxgo.Just("a")
+	rxgo.Just(1, 2, 3).Map(func(x int) int {
+		time.Sleep(10 * time.Millisecond)
+		return x
+	}).WithLatestFrom(latest, func(x int, y string) string {
+		return fmt.Sprint(x, y)
+	}).Subscribe(func(x string) {
+		res = append(res, x)
+	})
+
+	assert.Equal(t, []string{"1a", "2a", "3a"}, res, "WithLatestFrom Test Error!")
+}
+
+func TestJoin(t *testing.T) {
+	res := []string{}
+	rxgo.Just(1, 2).Join(rxgo.Just("a", "b"), time.Second, time.Second, func(l int, r string) string {
+		return fmt.Sprint(l, r)
+	}).Subscribe(func(x string) {
+		res = append(res, x)
+	})
+
+	assert.ElementsMatch(t, []string{"1a", "1b", "2a", "2b"}, res, "Join Test Error!")
+}
+
+func TestJoinWindowClosed(t *testing.T) {
+	res := []string{}
+	rxgo.Just(1).Join(rxgo.Just("a").Map(func(x string) string {
+		time.Sleep(20 * time.Millisecond)
+		return x
+	}), time.Millisecond, time.Millisecond, func(l int, r string) string {
+		return fmt.Sprint(l, r)
+	}).Subscribe(func(x string) {
+		res = append(res, x)
+	})
+
+	assert.Equal(t, []string{}, res, "Join Window Test Error!")
+}
+
+func TestGroupJoin(t *testing.T) {
+	res := []string{}
+	rxgo.Just(1, 2).GroupJoin(rxgo.Just("a", "b"), time.Second, time.Second, func(l int, rs []interface{}) string {
+		return fmt.Sprintf("%d:%d", l, len(rs))
+	}).Subscribe(func(x string) {
+		res = append(res, x)
+	})
+
+	assert.Equal(t, []string{"1:2", "2:2"}, res, "GroupJoin Test Error!")
+}
+
+func TestStartWith(t *testing.T) {
+	res := []int{}
+	rxgo.Just(3, 4).StartWith(1, 2).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+
+	assert.Equal(t, []int{1, 2, 3, 4}, res, "StartWith Test Error!")
+}
+
+func TestRepeat(t *testing.T) {
+	res := []int{}
+	rxgo.Just(1, 2).Map(func(x int) int {
+		return 10 * x
+	}).Repeat(3).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+
+	assert.Equal(t, []int{10, 20, 10, 20, 10, 20}, res, "Repeat Test Error!")
+}
+
+func TestRepeatWhen(t *testing.T) {
+	res := []int{}
+	rxgo.Just(1).RepeatWhen(rxgo.Just("again", "again")).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+
+	assert.Equal(t, []int{1, 1, 1}, res, "RepeatWhen Test Error!")
+}
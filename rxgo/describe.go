@@ -0,0 +1,165 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// kinds of operator that a Stage runs
+const (
+	KindSource    = "source"
+	KindTransform = "transform"
+	KindFiltering = "filtering"
+	KindCombining = "combining"
+)
+
+// Stage describes one Observable of a pipeline
+type Stage struct {
+	ID        int
+	Name      string
+	Kind      string
+	Threading ThreadModel
+	BufferLen uint
+	Inputs    []int // IDs of the stages whose items flow into this one
+}
+
+// Graph is the topology of a pipeline, stages are listed after their inputs
+type Graph struct {
+	Stages []Stage
+}
+
+// StageSnapshot is a Stage with the state of its output channel at some moment
+type StageSnapshot struct {
+	Stage
+	Connected bool // the output channel is allocated by a subscription
+	Fused     bool // the stage calls the next one directly and has no output channel
+	Len       int  // items waiting in the output channel
+}
+
+// Describe returns the topology of the pipeline that the Observable belongs to,
+// including the chains combined into it
+func (o *Observable) Describe() Graph {
+	var g Graph
+	for _, po := range o.stages() {
+		g.Stages = append(g.Stages, po.stage)
+	}
+	return g
+}
+
+// Snapshot returns the stages of the pipeline with the occupancy of their channels.
+// Read it while the pipeline is running to find out which stage is not consuming its input
+func (o *Observable) Snapshot() []StageSnapshot {
+	var res []StageSnapshot
+	for _, po := range o.stages() {
+		s := StageSnapshot{Stage: po.stage}
+		// the flows are replaced while the chain is connected again
+		po.o.root.connMu.Lock()
+		s.Fused = po.o.next != nil && po.o.next.fusedIn
+		if ch := po.o.outflow; ch != nil {
+			s.Connected, s.Len = true, len(ch)
+		}
+		po.o.root.connMu.Unlock()
+		res = append(res, s)
+	}
+	return res
+}
+
+type describedStage struct {
+	o     *Observable
+	stage Stage
+}
+
+func (o *Observable) stages() []describedStage {
+	var res []describedStage
+	ids := make(map[*Observable]int)
+
+	var walk func(root *Observable) (last int)
+	walk = func(root *Observable) (last int) {
+		last = -1
+		for po := root; po != nil; po = po.next {
+			if id, ok := ids[po]; ok {
+				last = id
+				continue
+			}
+			var inputs []int
+			if last >= 0 {
+				inputs = append(inputs, last)
+			}
+			for _, other := range po.others {
+				if id := walk(other.root); id >= 0 {
+					inputs = append(inputs, id)
+				}
+			}
+			last = len(res)
+			ids[po] = last
+			res = append(res, describedStage{po, Stage{
+				ID:        last,
+				Name:      po.Name,
+				Kind:      operatorKind(po.operator),
+				Threading: po.threading,
+				BufferLen: po.buf_len,
+				Inputs:    inputs,
+			}})
+		}
+		return
+	}
+	walk(o.root)
+	return res
+}
+
+func operatorKind(op streamOperator) string {
+	switch op.(type) {
+	case sourceOperater:
+		return KindSource
+	case transOperater:
+		return KindTransform
+	case filteringOperator:
+		return KindFiltering
+	case combiningOperator:
+		return KindCombining
+	default:
+		return fmt.Sprintf("%T", op)
+	}
+}
+
+// DOT exports the graph in the Graphviz DOT language
+func (g Graph) DOT() string {
+	var buf bytes.Buffer
+	buf.WriteString("digraph rxgo {\n\trankdir=LR;\n")
+	for _, s := range g.Stages {
+		label := strings.Replace(s.label(), `"`, `\"`, -1)
+		fmt.Fprintf(&buf, "\tn%d [label=\"%s\"];\n", s.ID, label)
+	}
+	for _, s := range g.Stages {
+		for _, in := range s.Inputs {
+			fmt.Fprintf(&buf, "\tn%d -> n%d;\n", in, s.ID)
+		}
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// Mermaid exports the graph as a Mermaid flowchart
+func (g Graph) Mermaid() string {
+	var buf bytes.Buffer
+	buf.WriteString("graph LR\n")
+	for _, s := range g.Stages {
+		label := strings.Replace(s.label(), `"`, "#quot;", -1)
+		fmt.Fprintf(&buf, "\tn%d[\"%s\"]\n", s.ID, label)
+	}
+	for _, s := range g.Stages {
+		for _, in := range s.Inputs {
+			fmt.Fprintf(&buf, "\tn%d --> n%d\n", in, s.ID)
+		}
+	}
+	return buf.String()
+}
+
+func (s Stage) label() string {
+	return fmt.Sprintf("%s (%s, %s, buf %d)", s.Name, s.Kind, s.Threading, s.BufferLen)
+}
@@ -0,0 +1,80 @@
+package rxgo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yilin0041/service-computing/rxgo"
+)
+
+func TestDescribe(t *testing.T) {
+	ob := rxgo.Just(1, 2).Map(func(x int) int {
+		return x
+	}).WithLatestFrom(rxgo.Just("a"), func(x int, y string) string {
+		return y
+	}).Distinct()
+
+	g := ob.Describe()
+	names := []string{}
+	kinds := []string{}
+	for _, s := range g.Stages {
+		names = append(names, s.Name)
+		kinds = append(kinds, s.Kind)
+	}
+	assert.Equal(t, []string{"Just", "map", "Just", "withLatestFrom", "distinct"}, names, "Describe Test Error!")
+	assert.Equal(t, []string{rxgo.KindSource, rxgo.KindTransform, rxgo.KindSource, rxgo.KindCombining, rxgo.KindFiltering}, kinds, "Describe Test Error!")
+	assert.Equal(t, []int{1, 2}, g.Stages[3].Inputs, "Describe Test Error!")
+
+	assert.True(t, strings.Contains(g.DOT(), "n1 -> n3;"), "DOT Test Error!")
+	assert.True(t, strings.Contains(g.Mermaid(), "n2 --> n3"), "Mermaid Test Error!")
+}
+
+func TestSnapshot(t *testing.T) {
+	ob := rxgo.Just(1, 2, 3).Map(func(x int) int {
+		return x
+	})
+	for _, s := range ob.Snapshot() {
+		assert.False(t, s.Connected, "Snapshot Test Error!")
+	}
+
+	blocked := make(chan bool)
+	release := make(chan bool)
+	go ob.Subscribe(func(x int) {
+		if x == 1 {
+			blocked <- true
+			<-release
+		}
+	})
+	<-blocked
+	snap := ob.Snapshot()
+	assert.True(t, snap[1].Connected, "Snapshot Test Error!")
+	assert.Equal(t, rxgo.BufferLen, snap[1].BufferLen, "Snapshot Test Error!")
+	close(release)
+}
+
+func TestSnapshotRepeat(t *testing.T) {
+	ob := rxgo.Range(0, 10).SetFusion(true).Map(func(x int) int {
+		return x
+	}).Map(func(x int) int {
+		return x
+	}).Repeat(100)
+
+	done := make(chan bool)
+	count := 0
+	go func() {
+		ob.Subscribe(func(x int) {
+			count++
+		})
+		close(done)
+	}()
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+			assert.Len(t, ob.Snapshot(), 4, "Snapshot Test Error!")
+		}
+	}
+	assert.Equal(t, 1000, count, "Snapshot Test Error!")
+}
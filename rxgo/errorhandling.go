@@ -0,0 +1,192 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// the circuit breaker is open and the call is not made,
+// it flows as FlowableError{Err: ErrCircuitOpen, Elements: item}
+var ErrCircuitOpen = errors.New("Circuit breaker is open")
+
+// BackoffPolicy decides whether and when RetryWithBackoff re-subscribes after an error
+type BackoffPolicy struct {
+	InitialDelay time.Duration
+	Multiplier   float64 // delay grows by Multiplier after each retry, values below 1 keep it constant
+	MaxDelay     time.Duration
+	Jitter       float64          // randomize each delay by up to ±Jitter of it, between 0 and 1
+	MaxAttempts  int              // subscriptions including the first one, zero or less means unlimited
+	Retryable    func(error) bool // nil retries any error
+}
+
+func (p BackoffPolicy) retryable(e error, attempt int) bool {
+	if p.MaxAttempts > 0 && attempt >= p.MaxAttempts {
+		return false
+	}
+	return p.Retryable == nil || p.Retryable(e)
+}
+
+// the delay before the retry after attempt
+func (p BackoffPolicy) delay(attempt int) time.Duration {
+	d := float64(p.InitialDelay)
+	if p.Multiplier > 1 {
+		d *= math.Pow(p.Multiplier, float64(attempt-1))
+	}
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d)
+}
+
+// RetryWithBackoff re-subscribes the Observable when an error item arrives, after a delay by policy.
+// The rest of the failed subscription is dropped. Once the policy gives up, the error flows
+// downstream like other items.
+func (parent *Observable) RetryWithBackoff(policy BackoffPolicy) (o *Observable) {
+	o = parent.newCombiningObservable("retryWithBackoff")
+	o.resubscribe = true
+	o.flip = policy
+	o.operator = retryOperator
+	return o
+}
+
+var retryOperator = combiningOperator{func(ctx context.Context, o *Observable, ins []chan interface{}, out chan interface{}) {
+	policy := o.flip.(BackoffPolicy)
+	in, cancel := ins[0], o.cancelUpstream
+
+	for attempt := 1; ; attempt++ {
+		retry := false
+		for x := range in {
+			if e, ok := x.(error); ok && policy.retryable(e, attempt) {
+				retry = true
+				break
+			}
+			if o.sendToFlow(ctx, x, out) {
+				break
+			}
+		}
+		cancel()
+		drainFlow(in)
+		if !retry {
+			return
+		}
+
+		select {
+		case <-o.after(policy.delay(attempt)):
+		case <-ctx.Done():
+			return
+		}
+		var upCtx context.Context
+		upCtx, cancel = context.WithCancel(ctx)
+		o.pred.reconnect(upCtx)
+		in = o.pred.outflow
+	}
+}}
+
+// state of a circuit breaker shared by all subscriptions of its Observable
+type circuitBreaker struct {
+	mu           sync.Mutex
+	maxFailures  int
+	resetTimeout time.Duration
+	failures     int
+	openUntil    time.Time
+	trying       bool // a trial call is made in half-open state
+}
+
+// report whether a call can be made now
+func (cb *circuitBreaker) allow(now time.Time) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.failures < cb.maxFailures {
+		return true
+	}
+	if now.Before(cb.openUntil) || cb.trying {
+		return false
+	}
+	cb.trying = true
+	return true
+}
+
+// end a call that neither succeeded nor failed, such as a skipped item, keeping the failure count
+func (cb *circuitBreaker) release() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.trying = false
+}
+
+func (cb *circuitBreaker) done(failed bool, now time.Time) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.trying = false
+	if !failed {
+		cb.failures = 0
+		return
+	}
+	cb.failures++
+	if cb.failures >= cb.maxFailures {
+		cb.openUntil = now.Add(cb.resetTimeout)
+	}
+}
+
+// CircuitBreaker maps each item by the function `func(x anytype) (anytype, error)` like Map, but after
+// maxFailures consecutive failures it stops calling the function for resetTimeout and emits
+// FlowableError{Err: ErrCircuitOpen} for each item instead. Then a single trial call decides whether
+// the breaker closes again. Errors returned by the function flow downstream as items.
+func (parent *Observable) CircuitBreaker(f interface{}, maxFailures int, resetTimeout time.Duration) (o *Observable) {
+	fv := reflect.ValueOf(f)
+	inType := []reflect.Type{typeAny}
+	outType := []reflect.Type{typeAny, typeError}
+	b, ctx_sup := checkFuncUpcast(fv, inType, outType, true)
+	if !b || maxFailures <= 0 {
+		panic(ErrFuncFlip)
+	}
+
+	o = parent.newTransformObservable("circuitBreaker")
+	o.flip_accept_error = checkFuncAcceptError(fv)
+
+	o.flip_sup_ctx = ctx_sup
+	o.flip = fv.Interface()
+	o.breaker = &circuitBreaker{maxFailures: maxFailures, resetTimeout: resetTimeout}
+	o.operator = circuitBreakerOperater
+	return o
+}
+
+var circuitBreakerOperater = transOperater{func(ctx context.Context, o *Observable, x reflect.Value, out chan interface{}) (end bool) {
+	if !o.breaker.allow(o.now()) {
+		return o.sendToFlow(ctx, FlowableError{Err: ErrCircuitOpen, Elements: x.Interface()}, out)
+	}
+
+	fv := reflect.ValueOf(o.flip)
+	var params = []reflect.Value{x}
+	if o.flip_sup_ctx {
+		params = []reflect.Value{reflect.ValueOf(ctx), x}
+	}
+	rs, skip, stop, e := userFuncCall(fv, params)
+	if stop || skip {
+		o.breaker.release()
+		return stop
+	}
+
+	var item interface{}
+	if e != nil {
+		item = e
+	} else if err, ok := rs[1].Interface().(error); ok && err != nil {
+		item = err
+		e = err
+	} else {
+		item = rs[0].Interface()
+	}
+	o.breaker.done(e != nil, o.now())
+	return o.sendToFlow(ctx, item, out)
+}}
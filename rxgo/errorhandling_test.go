@@ -0,0 +1,130 @@
+package rxgo_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yilin0041/service-computing/rxgo"
+)
+
+func TestRetryWithBackoff(t *testing.T) {
+	failures := 0
+	res := []interface{}{}
+	rxgo.Just(1, 2, 3).Map(func(x int) interface{} {
+		if x == 2 && failures < 2 {
+			failures++
+			return errors.New("flaky")
+		}
+		return x
+	}).RetryWithBackoff(rxgo.BackoffPolicy{
+		InitialDelay: time.Millisecond,
+		Multiplier:   2,
+	}).Subscribe(rxgo.ObserverMonitor{
+		Next: func(x interface{}) {
+			res = append(res, x)
+		},
+		Error: func(e error) {
+			res = append(res, e)
+		},
+	})
+
+	assert.Equal(t, []interface{}{1, 1, 1, 2, 3}, res, "RetryWithBackoff Test Error!")
+}
+
+func TestRetryWithBackoffGiveUp(t *testing.T) {
+	ee := errors.New("fatal")
+	res := []interface{}{}
+	rxgo.Just(1, 2).Map(func(x int) interface{} {
+		if x == 1 {
+			return ee
+		}
+		return x
+	}).RetryWithBackoff(rxgo.BackoffPolicy{
+		InitialDelay: time.Millisecond,
+		MaxAttempts:  3,
+		Retryable: func(e error) bool {
+			return e != ee
+		},
+	}).Subscribe(rxgo.ObserverMonitor{
+		Next: func(x interface{}) {
+			res = append(res, x)
+		},
+		Error: func(e error) {
+			res = append(res, e)
+		},
+	})
+
+	assert.Equal(t, []interface{}{ee, 2}, res, "RetryWithBackoff GiveUp Test Error!")
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	calls := 0
+	opened := 0
+	rxgo.Range(0, 10).CircuitBreaker(func(x int) (int, error) {
+		calls++
+		return 0, errors.New("down")
+	}, 3, time.Hour).Subscribe(rxgo.ObserverMonitor{
+		Error: func(e error) {
+			if errors.Is(e, rxgo.ErrCircuitOpen) {
+				opened++
+			}
+		},
+	})
+
+	assert.Equal(t, 3, calls, "CircuitBreaker Test Error!")
+	assert.Equal(t, 7, opened, "CircuitBreaker Test Error!")
+}
+
+func TestCircuitBreakerReset(t *testing.T) {
+	clock := rxgo.NewVirtualClock(time.Unix(0, 0))
+	reset := make(chan bool)
+	res := []int{}
+	rxgo.Range(0, 4).Map(func(x int) int {
+		if x == 2 {
+			<-reset
+		}
+		return x
+	}).CircuitBreaker(func(x int) (int, error) {
+		if x == 0 {
+			return 0, errors.New("down")
+		}
+		return x, nil
+	}, 1, 10*time.Millisecond).SetClock(clock).Subscribe(rxgo.ObserverMonitor{
+		Next: func(x interface{}) {
+			res = append(res, x.(int))
+		},
+		Error: func(e error) {
+			// item 1 is rejected, the breaker is half-open for item 2
+			if errors.Is(e, rxgo.ErrCircuitOpen) {
+				clock.Advance(20 * time.Millisecond)
+				close(reset)
+			}
+		},
+	})
+
+	assert.Equal(t, []int{2, 3}, res, "CircuitBreaker Reset Test Error!")
+}
+
+func TestCircuitBreakerSkip(t *testing.T) {
+	calls := 0
+	opened := 0
+	rxgo.Range(0, 6).CircuitBreaker(func(x int) (int, error) {
+		calls++
+		if x%2 == 1 {
+			panic(rxgo.ErrSkipItem)
+		}
+		return 0, errors.New("down")
+	}, 2, time.Hour).Subscribe(rxgo.ObserverMonitor{
+		Error: func(e error) {
+			if errors.Is(e, rxgo.ErrCircuitOpen) {
+				opened++
+			}
+		},
+	})
+
+	// skipped items neither fail nor close the breaker, so it opens after items 0 and 2
+	assert.Equal(t, 3, calls, "CircuitBreaker Skip Test Error!")
+	assert.Equal(t, 3, opened, "CircuitBreaker Skip Test Error!")
+}
@@ -13,17 +13,45 @@ type filteringOperator struct {
 }
 
 func (fop filteringOperator) op(ctx context.Context, o *Observable) {
-	in := o.pred.outflow
+	in := newFlowReader(o.pred.outflow)
 	out := o.outflow
 	var _out []interface{}
 	var wg sync.WaitGroup
 	go func() {
 		end := false
-		flag := make(map[interface{}]bool)
+		seen := newDistinctMemory(o.distinctSize, o.distinctTTL)
+		var lastKey interface{}
+		hasLast := false
+		if data := o.restoredState(); data != nil {
+			var st filteringState
+			if err := decodeState(data, &st); err != nil {
+				o.sendToFlow(ctx, err, out)
+			} else {
+				seen.restore(st.Seen, st.SeenAt)
+				lastKey, hasLast, _out = st.LastKey, st.HasLast, st.Out
+			}
+		}
 
 		timeStart := time.Now()
 		timeSample := time.Now()
-		for x := range in {
+		for x, ok := in.next(); ok; x, ok = in.next() {
+			if b, ok := x.(*checkpointBarrier); ok {
+				if !end {
+					wg.Wait()
+					st := filteringState{LastKey: lastKey, HasLast: hasLast}
+					st.Seen, st.SeenAt = seen.keys()
+					if o.elementAt > 0 || o.take != 0 || o.skip != 0 || o.last {
+						st.Out = _out
+					}
+					if data, err := encodeState(st); err != nil {
+						b.err = err
+					} else {
+						b.states[o.index()] = data
+					}
+					end = o.passBarrier(ctx, b, out)
+				}
+				continue
+			}
 			timeFromStart := time.Since(timeStart)
 			timeSampleFromStart := time.Since(timeSample)
 			timeStart = time.Now()
@@ -48,12 +76,48 @@ func (fop filteringOperator) op(ctx context.Context, o *Observable) {
 			o.mu.Lock()
 			_out = append(_out, x)
 			o.mu.Unlock()
-			if o.elementAt > 0 || o.take != 0 || o.skip != 0 || o.last || (o.distinct && flag[xv.Interface()]) {
+			if o.elementAt > 0 || o.take != 0 || o.skip != 0 || o.last {
 				continue
 			}
-			o.mu.Lock()
-			flag[xv.Interface()] = true
-			o.mu.Unlock()
+			if o.distinct || o.distinctUntil {
+				key, skip, stop, e := o.distinctKeyOf(xv)
+				if stop {
+					end = true
+					continue
+				}
+				if skip {
+					continue
+				}
+				if e != nil {
+					o.sendToFlow(ctx, e, out)
+					continue
+				}
+				if o.distinct && !seen.add(key, o.now()) {
+					continue
+				}
+				if o.distinctUntil {
+					changed := !hasLast
+					if hasLast {
+						equal, skip, stop, e := o.distinctKeyEqual(lastKey, key)
+						if stop {
+							end = true
+							continue
+						}
+						if skip {
+							continue
+						}
+						if e != nil {
+							o.sendToFlow(ctx, e, out)
+							continue
+						}
+						changed = !equal
+					}
+					lastKey, hasLast = key, true
+					if !changed {
+						continue
+					}
+				}
+			}
 			switch threading := o.threading; threading {
 			case ThreadingDefault:
 				if o.sample > 0 {
@@ -143,7 +207,7 @@ func (fop filteringOperator) op(ctx context.Context, o *Observable) {
 		if (o.last || o.first) && len(_out) == 0 && !o.flip_accept_error {
 			o.sendToFlow(ctx, errors.New("InputNotFound"), out)
 		}
-		o.closeFlow(out)
+		o.closeFlow(ctx, out)
 	}()
 }
 
@@ -181,15 +245,143 @@ func (parent *Observable) Debounce(_debounce time.Duration) (o *Observable) {
 	return o
 }
 
-// Distinct :suppress duplicate items emitted by an Observable
-func (parent *Observable) Distinct() (o *Observable) {
+// Distinct :suppress duplicate items emitted by an Observable.
+// An optional `func(x anytype) anytype` selects the comparable key that items are deduplicated by
+func (parent *Observable) Distinct(keyFunc ...interface{}) (o *Observable) {
 	o = parent.newFilteringObservable("distinct")
 	o.ignoreElement, o.first, o.last, o.distinct = false, false, false, true
 	o.debounce, o.take, o.skip = 0, 0, 0
+	o.distinctKey = checkKeyFunc(keyFunc...)
+	o.operator = filteringTotalOperator
+	return o
+}
+
+// SetDistinctMemory :bound the keys remembered by Distinct to the latest size keys, each for at most ttl.
+// Zero means no bound, which is the default
+func (o *Observable) SetDistinctMemory(size int, ttl time.Duration) *Observable {
+	o.distinctSize, o.distinctTTL = size, ttl
+	return o
+}
+
+// DistinctUntilChanged :suppress items equal to the item just before them.
+// keyFunc `func(x anytype) anytype` selects the key to compare and equalFunc `func(a, b anytype) bool`
+// compares two keys; either may be nil to compare the items themselves with reflect.DeepEqual
+func (parent *Observable) DistinctUntilChanged(keyFunc, equalFunc interface{}) (o *Observable) {
+	o = parent.newFilteringObservable("distinctUntilChanged")
+	o.ignoreElement, o.first, o.last, o.distinct, o.distinctUntil = false, false, false, false, true
+	o.debounce, o.take, o.skip = 0, 0, 0
+	if keyFunc != nil {
+		o.distinctKey = checkKeyFunc(keyFunc)
+	}
+	if equalFunc != nil {
+		fv := reflect.ValueOf(equalFunc)
+		if b, _ := checkFuncUpcast(fv, []reflect.Type{typeAny, typeAny}, []reflect.Type{typeBool}, false); !b {
+			panic(ErrFuncFlip)
+		}
+		o.distinctEqual = fv.Interface()
+	}
 	o.operator = filteringTotalOperator
 	return o
 }
 
+func checkKeyFunc(keyFunc ...interface{}) interface{} {
+	if len(keyFunc) == 0 {
+		return nil
+	}
+	fv := reflect.ValueOf(keyFunc[0])
+	if b, _ := checkFuncUpcast(fv, []reflect.Type{typeAny}, []reflect.Type{typeAny}, false); len(keyFunc) > 1 || !b {
+		panic(ErrFuncFlip)
+	}
+	return fv.Interface()
+}
+
+func (o *Observable) distinctKeyOf(x reflect.Value) (key interface{}, skip, stop bool, e error) {
+	if o.distinctKey == nil {
+		return x.Interface(), false, false, nil
+	}
+	rs, skip, stop, e := userFuncCall(reflect.ValueOf(o.distinctKey), []reflect.Value{x})
+	if skip || stop || e != nil {
+		return nil, skip, stop, e
+	}
+	return rs[0].Interface(), false, false, nil
+}
+
+// report whether the key a and b are equal
+func (o *Observable) distinctKeyEqual(a, b interface{}) (equal, skip, stop bool, e error) {
+	if o.distinctEqual == nil {
+		return reflect.DeepEqual(a, b), false, false, nil
+	}
+	fv := reflect.ValueOf(o.distinctEqual)
+	params := []reflect.Value{reflect.ValueOf(a), reflect.ValueOf(b)}
+	for i, p := range params {
+		if !p.IsValid() {
+			// a nil key
+			params[i] = reflect.Zero(fv.Type().In(i))
+		}
+	}
+	rs, skip, stop, e := userFuncCall(fv, params)
+	if skip || stop || e != nil {
+		return false, skip, stop, e
+	}
+	return rs[0].Bool(), false, false, nil
+}
+
+// keys seen by Distinct in the order they arrived, so the oldest can be forgotten first
+type distinctMemory struct {
+	size  int
+	ttl   time.Duration
+	seen  map[interface{}]time.Time
+	order []interface{}
+}
+
+func newDistinctMemory(size int, ttl time.Duration) *distinctMemory {
+	return &distinctMemory{size: size, ttl: ttl, seen: make(map[interface{}]time.Time)}
+}
+
+// keys remembered and when they are seen
+func (m *distinctMemory) keys() (keys []interface{}, at []time.Time) {
+	if m.size > 0 || m.ttl > 0 {
+		keys = m.order
+	} else {
+		for k := range m.seen {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range keys {
+		at = append(at, m.seen[k])
+	}
+	return
+}
+
+func (m *distinctMemory) restore(keys []interface{}, at []time.Time) {
+	for i, k := range keys {
+		m.seen[k] = at[i]
+		if m.size > 0 || m.ttl > 0 {
+			m.order = append(m.order, k)
+		}
+	}
+}
+
+// add key and report whether it is not seen before
+func (m *distinctMemory) add(key interface{}, now time.Time) bool {
+	for m.ttl > 0 && len(m.order) > 0 && now.Sub(m.seen[m.order[0]]) >= m.ttl {
+		delete(m.seen, m.order[0])
+		m.order = m.order[1:]
+	}
+	if _, ok := m.seen[key]; ok {
+		return false
+	}
+	m.seen[key] = now
+	if m.size > 0 || m.ttl > 0 {
+		m.order = append(m.order, key)
+	}
+	if m.size > 0 && len(m.order) > m.size {
+		delete(m.seen, m.order[0])
+		m.order = m.order[1:]
+	}
+	return true
+}
+
 // ElementAt :emit only item n emitted by an Observable
 func (parent *Observable) ElementAt(index int) (o *Observable) {
 	o = parent.newFilteringObservable("elementAt")
@@ -31,6 +31,79 @@ func TestDistinct(t *testing.T) {
 	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6}, res, "Distinct Test Error!")
 }
 
+func TestDistinctByKey(t *testing.T) {
+	type user struct {
+		ID   int
+		Name string
+	}
+	res := []string{}
+	rxgo.Just(user{1, "a"}, user{2, "b"}, user{1, "c"}).Distinct(func(x user) int {
+		return x.ID
+	}).Subscribe(func(x user) {
+		res = append(res, x.Name)
+	})
+	assert.Equal(t, []string{"a", "b"}, res, "Distinct Key Test Error!")
+}
+
+func TestDistinctMemory(t *testing.T) {
+	res := []int{}
+	rxgo.Just(0, 1, 2, 0, 2).Distinct().SetDistinctMemory(2, 0).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+	assert.Equal(t, []int{0, 1, 2, 0}, res, "Distinct Memory Test Error!")
+}
+
+func TestDistinctMemoryTTL(t *testing.T) {
+	// each item arrives a second after the one before it
+	clock := rxgo.NewVirtualClock(time.Unix(0, 0))
+	res := []int{}
+	rxgo.Just(0, 1, 2, 0, 2, 2).Distinct(func(x int) int {
+		clock.Advance(time.Second)
+		return x
+	}).SetDistinctMemory(2, 3*time.Second).SetClock(clock).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+	// 0 is forgotten by size at 3s, the 2 at 5s is within ttl and the 2 at 6s is not
+	assert.Equal(t, []int{0, 1, 2, 0, 2}, res, "Distinct Memory TTL Test Error!")
+}
+
+func TestDistinctUntilChanged(t *testing.T) {
+	res := []int{}
+	rxgo.Just(0, 0, 1, 1, 0, 2, 2).DistinctUntilChanged(nil, nil).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+	assert.Equal(t, []int{0, 1, 0, 2}, res, "DistinctUntilChanged Test Error!")
+
+	res = []int{}
+	rxgo.Just(1, 3, 4, 6, 7).DistinctUntilChanged(func(x int) bool {
+		return x%2 == 0
+	}, func(a, b bool) bool {
+		return a == b
+	}).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+	assert.Equal(t, []int{1, 4, 7}, res, "DistinctUntilChanged Key Test Error!")
+
+	strs := []string{}
+	rxgo.Just("a", "", "b", "c", "d", "e").DistinctUntilChanged(func(x string) interface{} {
+		if x == "" {
+			return nil
+		}
+		return x
+	}, func(a, b interface{}) bool {
+		switch b {
+		case "c":
+			panic(rxgo.ErrSkipItem)
+		case "d":
+			panic(rxgo.ErrEoFlow)
+		}
+		return a == b
+	}).Subscribe(func(x string) {
+		strs = append(strs, x)
+	})
+	assert.Equal(t, []string{"a", "", "b"}, strs, "DistinctUntilChanged Equal Test Error!")
+}
+
 func TestElementAt(t *testing.T) {
 	res := []int{}
 	ob := rxgo.Just(0, 1, 2, 3, 4, 5).Map(func(x int) int {
@@ -25,7 +25,8 @@ func (sop sourceOperater) op(ctx context.Context, o *Observable) {
 		for end := false; !end; { // made panic op re-enter
 			end = sop.opFunc(ctx, o, out)
 		}
-		o.closeFlow(out)
+		o.sendFinalBarrier(ctx, out)
+		o.closeFlow(ctx, out)
 	}()
 }
 
@@ -102,6 +103,7 @@ var startSource = sourceOperater{func(ctx context.Context, o *Observable, out ch
 // Range creates an Observable that emits a particular range of sequential integers.
 func Range(start, end int) *Observable {
 	o := newGeneratorObservable("Range")
+	o.replayable = true
 
 	o.flip = func(ctx context.Context, out chan interface{}) {
 		i := start
@@ -126,6 +128,7 @@ var rangeSource = sourceOperater{func(ctx context.Context, o *Observable, out ch
 // Just creates an Observable with the provided item(s).
 func Just(items ...interface{}) *Observable {
 	o := newGeneratorObservable("Just")
+	o.replayable = true
 
 	o.flip = func(ctx context.Context, out chan interface{}) {
 		for _, item := range items {
@@ -150,6 +153,7 @@ func From(items interface{}) *Observable {
 	if v.Kind() == reflect.Slice {
 		length := v.Len()
 		o := newGeneratorObservable("From Slice")
+		o.replayable = true
 
 		o.flip = func(ctx context.Context, out chan interface{}) {
 			i := 0
@@ -239,6 +243,7 @@ var throwSource = rangeSource
 // create an Observable that emits no items but terminates normally
 func Empty() *Observable {
 	o := newGeneratorObservable("Empty")
+	o.replayable = true
 
 	o.flip = func(ctx context.Context, out chan interface{}) {
 	}
@@ -249,6 +254,7 @@ func Empty() *Observable {
 // create an Observable that emits no items and terminates with an error
 func Throw(e error) *Observable {
 	o := newGeneratorObservable("Throw")
+	o.replayable = true
 
 	o.flip = func(ctx context.Context, out chan interface{}) {
 		item := e
@@ -0,0 +1,204 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// StreamFormat is how StreamHandler writes items to the response
+type StreamFormat int
+
+const (
+	FormatAuto   StreamFormat = iota // SSE if the request accepts text/event-stream, NDJSON otherwise
+	FormatSSE                        // Server-Sent Events, an item per `data:` event and errors as `error` events
+	FormatNDJSON                     // a JSON value per line and errors as {"error": "..."}
+)
+
+// StreamHandler is an http.Handler that subscribes to the Observable built for each request and streams
+// its items as JSON. The subscription is cancelled when the client disconnects.
+type StreamHandler struct {
+	Observable func(r *http.Request) *Observable
+	Format     StreamFormat
+}
+
+var _ http.Handler = StreamHandler{}
+
+func (h StreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	format := h.Format
+	if format == FormatAuto {
+		format = FormatNDJSON
+		if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+			format = FormatSSE
+		}
+	}
+	if format == FormatSSE {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush() // let the client see the headers before the first item
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	write := func(event string, v interface{}) {
+		if ctx.Err() != nil {
+			return
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			event, data = "error", []byte(fmt.Sprintf("%q", err.Error()))
+		}
+		if format == FormatSSE {
+			if event != "" {
+				_, err = fmt.Fprintf(w, "event: %s\n", event)
+			}
+			if err == nil {
+				_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+			}
+		} else {
+			if event == "error" {
+				data = []byte(fmt.Sprintf("{\"error\":%s}", data))
+			}
+			_, err = fmt.Fprintf(w, "%s\n", data)
+		}
+		if err != nil {
+			cancel() // the client is gone
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	h.Observable(r).Subscribe(ObserverMonitor{
+		Next: func(x interface{}) {
+			write("", x)
+		},
+		Error: func(e error) {
+			write("error", e.Error())
+		},
+		Context: func() context.Context {
+			return ctx
+		},
+	})
+}
+
+// FromSSE creates an Observable that emits the data of each Server-Sent Event read from body as
+// json.RawMessage, and `error` events as errors. body is closed when the flow ends.
+func FromSSE(body io.Reader) *Observable {
+	return fromStream("From SSE", body, func(sc *bufio.Scanner, emit func(x interface{}) bool) {
+		var event string
+		var data [][]byte
+		for sc.Scan() {
+			line := sc.Bytes()
+			switch {
+			case len(line) == 0:
+				if data != nil && emit(streamItem(event, bytes.Join(data, []byte("\n")))) {
+					return
+				}
+				event, data = "", nil
+			case line[0] == ':':
+			default:
+				field, value := string(line), ""
+				if i := bytes.IndexByte(line, ':'); i >= 0 {
+					field, value = string(line[:i]), strings.TrimPrefix(string(line[i+1:]), " ")
+				}
+				switch field {
+				case "event":
+					event = value
+				case "data":
+					data = append(data, []byte(value))
+				}
+			}
+		}
+		if data != nil {
+			emit(streamItem(event, bytes.Join(data, []byte("\n"))))
+		}
+	})
+}
+
+// FromNDJSON creates an Observable that emits each line of body as json.RawMessage, and lines
+// like {"error": "..."} as errors. body is closed when the flow ends.
+func FromNDJSON(body io.Reader) *Observable {
+	return fromStream("From NDJSON", body, func(sc *bufio.Scanner, emit func(x interface{}) bool) {
+		for sc.Scan() {
+			line := bytes.TrimSpace(sc.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			var e struct {
+				Error *string `json:"error"`
+			}
+			if line[0] == '{' && json.Unmarshal(line, &e) == nil && e.Error != nil {
+				if emit(errors.New(*e.Error)) {
+					return
+				}
+				continue
+			}
+			if emit(json.RawMessage(append([]byte(nil), line...))) {
+				return
+			}
+		}
+	})
+}
+
+func streamItem(event string, data []byte) interface{} {
+	if event == "error" {
+		var msg string
+		if json.Unmarshal(data, &msg) != nil {
+			msg = string(data)
+		}
+		return errors.New(msg)
+	}
+	return json.RawMessage(data)
+}
+
+var fromStreamSource = rangeSource
+
+func fromStream(name string, body io.Reader, scan func(sc *bufio.Scanner, emit func(x interface{}) bool)) *Observable {
+	o := newGeneratorObservable(name)
+
+	o.flip = func(ctx context.Context, out chan interface{}) {
+		closer, _ := body.(io.Closer)
+		done := make(chan bool)
+		defer close(done)
+		if closer != nil {
+			defer closer.Close()
+			// a blocked read only returns when the body is closed
+			go func() {
+				select {
+				case <-ctx.Done():
+					closer.Close()
+				case <-done:
+				}
+			}()
+		}
+
+		sc := bufio.NewScanner(body)
+		sc.Buffer(make([]byte, 64*1024), 1024*1024)
+		scan(sc, func(x interface{}) bool {
+			return o.sendToFlow(ctx, x, out)
+		})
+		if err := sc.Err(); err != nil && ctx.Err() == nil {
+			o.sendToFlow(ctx, err, out)
+		}
+	}
+	o.operator = fromStreamSource
+	return o
+}
@@ -0,0 +1,87 @@
+package rxgo_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yilin0041/service-computing/rxgo"
+)
+
+func streamServer() *httptest.Server {
+	return httptest.NewServer(rxgo.StreamHandler{
+		Observable: func(r *http.Request) *rxgo.Observable {
+			return rxgo.Just(1, "a", errors.New("bad"), map[string]int{"b": 2})
+		},
+	})
+}
+
+func collectStream(ob *rxgo.Observable) []string {
+	res := []string{}
+	ob.Subscribe(rxgo.ObserverMonitor{
+		Next: func(x interface{}) {
+			res = append(res, string(x.(json.RawMessage)))
+		},
+		Error: func(e error) {
+			res = append(res, "error: "+e.Error())
+		},
+	})
+	return res
+}
+
+func TestStreamSSE(t *testing.T) {
+	ts := streamServer()
+	defer ts.Close()
+
+	req, _ := http.NewRequest("GET", ts.URL, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	res := collectStream(rxgo.FromSSE(resp.Body))
+	assert.Equal(t, []string{"1", `"a"`, "error: bad", `{"b":2}`}, res, "SSE Test Error!")
+}
+
+func TestStreamNDJSON(t *testing.T) {
+	ts := streamServer()
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	res := collectStream(rxgo.FromNDJSON(resp.Body))
+	assert.Equal(t, []string{"1", `"a"`, "error: bad", `{"b":2}`}, res, "NDJSON Test Error!")
+}
+
+func TestStreamDisconnect(t *testing.T) {
+	disposed := make(chan bool, 1)
+	ts := httptest.NewServer(rxgo.StreamHandler{
+		Observable: func(r *http.Request) *rxgo.Observable {
+			return rxgo.Never().DoOnDispose(func() {
+				disposed <- true
+			})
+		},
+		Format: rxgo.FormatSSE,
+	})
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequest("GET", ts.URL, nil)
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	assert.NoError(t, err)
+	cancel()
+	resp.Body.Close()
+
+	select {
+	case <-disposed:
+	case <-time.After(5 * time.Second):
+		t.Errorf("Subscription not cancelled after client disconnected")
+	}
+}
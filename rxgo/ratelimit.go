@@ -0,0 +1,130 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// a token bucket refilled by rate tokens per second up to burst tokens
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: now}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if now.After(b.last) {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+}
+
+// reserve a token and return how long to wait for it. Tokens may go negative,
+// so the items waiting on a bucket pass in the order they reserved
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.refill(now)
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// buckets of a rate limiting Observable, shared by all its subscriptions
+type rateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   int
+	keyFunc interface{}
+	buckets map[interface{}]*tokenBucket
+}
+
+// idle buckets are full and the same as new ones, forget them when the map grows
+const rateLimitPruneLen = 1024
+
+func (l *rateLimiter) reserve(key interface{}, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= rateLimitPruneLen {
+			for k, ob := range l.buckets {
+				if ob.refill(now); ob.tokens >= ob.burst {
+					delete(l.buckets, k)
+				}
+			}
+		}
+		b = newTokenBucket(l.rate, l.burst, now)
+		l.buckets[key] = b
+	}
+	return b.reserve(now)
+}
+
+// RateLimit delays items so that at most rate items per second pass, with bursts of up to burst items.
+// Items are delayed, never dropped, and keep their order.
+func (parent *Observable) RateLimit(rate float64, burst int) (o *Observable) {
+	return parent.newRateLimitObservable("rateLimit", nil, rate, burst)
+}
+
+// RateLimitBy is like RateLimit, but each key selected by `func(x anytype) anytype` has its own limit.
+// With ThreadingDefault an item waiting for its key also holds back the items behind it;
+// use SubscribeOn(ThreadingIO) to let other keys pass, at the cost of the order of items.
+func (parent *Observable) RateLimitBy(keyFunc interface{}, rate float64, burst int) (o *Observable) {
+	fv := reflect.ValueOf(keyFunc)
+	if b, _ := checkFuncUpcast(fv, []reflect.Type{typeAny}, []reflect.Type{typeAny}, false); !b {
+		panic(ErrFuncFlip)
+	}
+	return parent.newRateLimitObservable("rateLimitBy", fv.Interface(), rate, burst)
+}
+
+func (parent *Observable) newRateLimitObservable(name string, keyFunc interface{}, rate float64, burst int) (o *Observable) {
+	if rate <= 0 || burst <= 0 {
+		panic(ErrFuncFlip)
+	}
+	o = parent.newTransformObservable(name)
+	o.flip = &rateLimiter{rate: rate, burst: burst, keyFunc: keyFunc, buckets: make(map[interface{}]*tokenBucket)}
+	o.operator = rateLimitOperater
+	return o
+}
+
+var rateLimitOperater = transOperater{func(ctx context.Context, o *Observable, x reflect.Value, out chan interface{}) (end bool) {
+	l := o.flip.(*rateLimiter)
+
+	var key interface{}
+	if l.keyFunc != nil {
+		rs, skip, stop, e := userFuncCall(reflect.ValueOf(l.keyFunc), []reflect.Value{x})
+		if stop {
+			return true
+		}
+		if skip {
+			return
+		}
+		if e != nil {
+			return o.sendToFlow(ctx, e, out)
+		}
+		key = rs[0].Interface()
+	}
+
+	if wait := l.reserve(key, o.now()); wait > 0 {
+		select {
+		case <-o.after(wait):
+		case <-ctx.Done():
+			return true
+		}
+	}
+	return o.sendToFlow(ctx, x.Interface(), out)
+}}
@@ -0,0 +1,65 @@
+package rxgo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yilin0041/service-computing/rxgo"
+)
+
+// subscribe ob and advance clock by step whenever the flow waits on it and no item is arriving,
+// returns the virtual time each item arrives
+func subscribeVirtual(ob *rxgo.Observable, clock *rxgo.VirtualClock, step time.Duration) map[interface{}]time.Duration {
+	start := clock.Now()
+	items := make(chan interface{})
+	go func() {
+		ob.Subscribe(func(x interface{}) {
+			items <- x
+		})
+		close(items)
+	}()
+
+	res := make(map[interface{}]time.Duration)
+	for {
+		select {
+		case x, ok := <-items:
+			if !ok {
+				return res
+			}
+			res[x] = clock.Now().Sub(start)
+		case <-time.After(time.Millisecond):
+			if clock.Waiters() > 0 {
+				clock.Advance(step)
+			}
+		}
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	clock := rxgo.NewVirtualClock(time.Unix(0, 0))
+	ob := rxgo.Just(1, 2, 3, 4).RateLimit(2, 2).SetClock(clock)
+
+	res := subscribeVirtual(ob, clock, 100*time.Millisecond)
+	assert.Equal(t, map[interface{}]time.Duration{
+		1: 0,
+		2: 0,
+		3: 500 * time.Millisecond,
+		4: time.Second,
+	}, res, "RateLimit Test Error!")
+}
+
+func TestRateLimitBy(t *testing.T) {
+	clock := rxgo.NewVirtualClock(time.Unix(0, 0))
+	ob := rxgo.Just("a1", "b1", "a2", "b2").RateLimitBy(func(x string) byte {
+		return x[0]
+	}, 1, 1).SetClock(clock)
+
+	res := subscribeVirtual(ob, clock, 100*time.Millisecond)
+	assert.Equal(t, map[interface{}]time.Duration{
+		"a1": 0,
+		"b1": 0,
+		"a2": time.Second,
+		"b2": time.Second,
+	}, res, "RateLimitBy Test Error!")
+}
@@ -0,0 +1,92 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"context"
+	"reflect"
+)
+
+// Using creates an Observable whose items come from the Observable built on a resource, and
+// ties the resource's lifetime to the subscription. resourceFactory `func() (anytype, error)`
+// opens the resource when subscribed, observableFactory `func(r anytype) *Observable` builds the
+// Observable on it, and dispose `func(r anytype)` releases it exactly once when the flow completes
+// or the observer unsubscribes. If resourceFactory fails, its error is emitted and nothing is disposed.
+func Using(resourceFactory, observableFactory, dispose interface{}) *Observable {
+	rf, of, df := reflect.ValueOf(resourceFactory), reflect.ValueOf(observableFactory), reflect.ValueOf(dispose)
+	if b, _ := checkFuncUpcast(rf, []reflect.Type{}, []reflect.Type{typeAny, typeError}, false); !b {
+		panic(ErrFuncFlip)
+	}
+	if b, _ := checkFuncUpcast(of, []reflect.Type{typeAny}, []reflect.Type{typeObservable}, false); !b {
+		panic(ErrFuncFlip)
+	}
+	if b, _ := checkFuncUpcast(df, []reflect.Type{typeAny}, []reflect.Type{}, false); !b {
+		panic(ErrFuncFlip)
+	}
+
+	o := newGeneratorObservable("Using")
+	o.flip = func(ctx context.Context, out chan interface{}) {
+		rs := rf.Call([]reflect.Value{})
+		if e, ok := rs[1].Interface().(error); ok && e != nil {
+			o.sendToFlow(ctx, e, out)
+			return
+		}
+		resource := rs[0]
+		defer df.Call([]reflect.Value{resource})
+
+		ro := of.Call([]reflect.Value{resource})[0].Interface().(*Observable)
+		if ro == nil {
+			return
+		}
+		for ; ro.next != nil; ro = ro.next {
+		}
+		ro.mu.Lock()
+		ro.connect(ctx)
+		ch := ro.outflow
+		ro.mu.Unlock()
+		for item := range ch {
+			if b := o.sendToFlow(ctx, item, out); b {
+				// the source may still use the resource, wait for it before disposing
+				for range ch {
+				}
+				return
+			}
+		}
+	}
+	o.operator = usingSource
+	return o
+}
+
+var usingSource = rangeSource
+
+// Finally calls action exactly once each time the flow ends, whether it completes,
+// carries an error to the end, or is cancelled by the observer's context.
+func (parent *Observable) Finally(action func()) (o *Observable) {
+	o = parent.newTransformObservable("finally")
+	o.flip_accept_error = true
+	o.closers = []func(ctx context.Context){func(ctx context.Context) {
+		action()
+	}}
+	o.operator = passOperater
+	return o
+}
+
+// DoOnDispose calls action once when the observer unsubscribes, that is the context
+// is cancelled before the flow ends.
+func (parent *Observable) DoOnDispose(action func()) (o *Observable) {
+	o = parent.newTransformObservable("doOnDispose")
+	o.flip_accept_error = true
+	o.closers = []func(ctx context.Context){func(ctx context.Context) {
+		if ctx.Err() != nil {
+			action()
+		}
+	}}
+	o.operator = passOperater
+	return o
+}
+
+var passOperater = transOperater{func(ctx context.Context, o *Observable, x reflect.Value, out chan interface{}) (end bool) {
+	return o.sendToFlow(ctx, x.Interface(), out)
+}}
@@ -0,0 +1,139 @@
+package rxgo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yilin0041/service-computing/rxgo"
+)
+
+type cursor struct {
+	rows   []int
+	closed int
+}
+
+func TestUsing(t *testing.T) {
+	c := &cursor{rows: []int{1, 2, 3}}
+	res := []int{}
+	rxgo.Using(func() (*cursor, error) {
+		return c, nil
+	}, func(c *cursor) *rxgo.Observable {
+		return rxgo.From(c.rows)
+	}, func(c *cursor) {
+		c.closed++
+	}).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+
+	assert.Equal(t, []int{1, 2, 3}, res, "Using Test Error!")
+	assert.Equal(t, 1, c.closed, "Using Dispose Test Error!")
+}
+
+func TestUsingWithError(t *testing.T) {
+	var ee error
+	rxgo.Using(func() (*cursor, error) {
+		return nil, errors.New("no cursor")
+	}, func(c *cursor) *rxgo.Observable {
+		t.Errorf("No observable expected!")
+		return nil
+	}, func(c *cursor) {
+		t.Errorf("No dispose expected!")
+	}).Subscribe(rxgo.ObserverMonitor{
+		Error: func(e error) {
+			ee = e
+		},
+	})
+
+	assert.Error(t, ee, "Using Error Test Error!")
+}
+
+func TestUsingWithCancel(t *testing.T) {
+	c := &cursor{}
+	var observer = rxgo.ObserverMonitor{}
+	observer.Next = func(x interface{}) {
+		observer.Unsubscribe()
+	}
+	observer.Context = func() context.Context {
+		ctx, cancel := context.WithCancel(context.Background())
+		observer.CancelObservables = cancel
+		return ctx
+	}
+
+	rxgo.Using(func() (*cursor, error) {
+		return c, nil
+	}, func(c *cursor) *rxgo.Observable {
+		return rxgo.Range(0, 1000)
+	}, func(c *cursor) {
+		c.closed++
+	}).Subscribe(observer)
+
+	assert.Equal(t, 1, c.closed, "Using Cancel Test Error!")
+}
+
+func TestUsingDisposeAfterSource(t *testing.T) {
+	c := &cursor{rows: make([]int, 100)}
+	read := -1
+	var observer = rxgo.ObserverMonitor{}
+	observer.Next = func(x interface{}) {
+		observer.Unsubscribe()
+	}
+	observer.Context = func() context.Context {
+		ctx, cancel := context.WithCancel(context.Background())
+		observer.CancelObservables = cancel
+		return ctx
+	}
+
+	rxgo.Using(func() (*cursor, error) {
+		return c, nil
+	}, func(c *cursor) *rxgo.Observable {
+		return rxgo.Generator(func(ctx context.Context, send func(x interface{}) bool) {
+			// keep reading the cursor after the observer unsubscribes
+			for _, row := range c.rows {
+				send(row)
+			}
+			read = c.closed
+		})
+	}, func(c *cursor) {
+		c.closed++
+	}).Subscribe(observer)
+
+	assert.Equal(t, 0, read, "Using Dispose Test Error!")
+	assert.Equal(t, 1, c.closed, "Using Dispose Test Error!")
+}
+
+func TestFinally(t *testing.T) {
+	count := 0
+	res := []int{}
+	rxgo.Just(1, 2).Finally(func() {
+		count++
+	}).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+
+	assert.Equal(t, []int{1, 2}, res, "Finally Test Error!")
+	assert.Equal(t, 1, count, "Finally Test Error!")
+}
+
+func TestDoOnDispose(t *testing.T) {
+	count := 0
+	rxgo.Just(1, 2).DoOnDispose(func() {
+		count++
+	}).Subscribe(func(x int) {})
+	assert.Equal(t, 0, count, "DoOnDispose Test Error!")
+
+	var observer = rxgo.ObserverMonitor{}
+	observer.Context = func() context.Context {
+		ctx, cancel := context.WithCancel(context.Background())
+		observer.CancelObservables = cancel
+		return ctx
+	}
+	observer.AfterConnected = func() {
+		go observer.Unsubscribe()
+	}
+	rxgo.Never().DoOnDispose(func() {
+		count++
+	}).Subscribe(observer)
+	assert.Equal(t, 1, count, "DoOnDispose Test Error!")
+}
@@ -21,6 +21,18 @@ const (
 	ThreadingComputing                    // each item served by one goroutine in a limited group
 )
 
+func (t ThreadModel) String() string {
+	switch t {
+	case ThreadingDefault:
+		return "default"
+	case ThreadingIO:
+		return "io"
+	case ThreadingComputing:
+		return "computing"
+	}
+	return "unknown"
+}
+
 // Subscribe paeameter error
 var ErrFuncOnNext = errors.New("Subscribe paramteter needs func(x anytype) or Observer or ObserverWithContext")
 
@@ -43,6 +55,10 @@ func (e FlowableError) Error() string {
 	return e.Err.Error()
 }
 
+func (e FlowableError) Unwrap() error {
+	return e.Err
+}
+
 // Observer subscribes to an Observable. Then that observer reacts to whatever item or sequence of items the Observable emits.
 type Observer interface {
 	OnNext(x interface{})
@@ -109,10 +125,10 @@ type streamOperator interface {
 	op(ctx context.Context, o *Observable)
 }
 
-//emit something
+// emit something
 type sourceFunc func(ctx context.Context, send func(x interface{}) (endSignal bool))
 
-//transform any item
+// transform any item
 type transformFunc func(ctx context.Context, item interface{}, send func(x interface{}) (endSignal bool))
 
 // default buffer of channels
@@ -124,19 +140,36 @@ var BufferLen uint = 128
 type Observable struct {
 	Name string
 	mu   sync.Mutex // lock all when creating subscriber
+	// held on the source while its chain is connected, a pass of Repeat or RetryWithBackoff connects it again
+	connMu sync.Mutex
 	//
 	flip     interface{} // transformation function
 	outflow  chan interface{}
 	operator streamOperator
 	// chain of Observables
-	root *Observable
-	next *Observable
-	pred *Observable
+	root   *Observable
+	next   *Observable
+	pred   *Observable
+	others []*Observable // other predecessors of a combining Observable
 	// control model
 	threading ThreadModel //threading model. if this is root, it represents obseverOn model
 	buf_len   uint
 	// utility vars
 	debug             Observer
+	closers           []func(ctx context.Context) // run by closeFlow each time the flow ends
+	resubscribe       bool                        // the operator re-subscribes its upstream
+	cancelUpstream    context.CancelFunc          // cancel the current pass of upstream, if resubscribe
+	breaker           *circuitBreaker
+	clock             Clock             // time source of time-based operators, SystemClock if nil
+	checkpoint        *checkpointConfig // set on the source and the Checkpoint stage of a pipeline
+	replayable        bool              // the source emits the same items when subscribed again
+	seek              func(position int) error
+	fusion            bool          // set on the source, fuse synchronous transform stages when connected
+	fused             []*Observable // the stages after this one that run in its goroutine when connected
+	fusedIn           bool          // this stage runs in the goroutine of a previous one when connected
+	batchSize         int
+	batchLatency      time.Duration
+	batched           bool // items are sent in batches when connected
 	flip_sup_ctx      bool //indicate that flip function use context as first paramter
 	flip_accept_error bool // indicate that flip function input's data is type interface{} or error
 	debounce          time.Duration
@@ -149,6 +182,14 @@ type Observable struct {
 	skip              int
 	take              int
 	takeOrSkip        bool
+	leftWindow        time.Duration
+	rightWindow       time.Duration
+	repeat            int
+	distinctUntil     bool
+	distinctKey       interface{} // key selector of Distinct and DistinctUntilChanged
+	distinctEqual     interface{} // comparer of DistinctUntilChanged
+	distinctSize      int
+	distinctTTL       time.Duration
 }
 
 func newObservable() *Observable {
@@ -157,13 +198,109 @@ func newObservable() *Observable {
 
 // connect all Observable form the first one.
 func (o *Observable) connect(ctx context.Context) {
-	for po := o.root; po != nil; po = po.next {
-		po.outflow = make(chan interface{}, po.buf_len)
-		po.operator.op(ctx, po)
-		//fmt.Println("conneted", po.name, po.outflow)
+	connectStages(ctx, o.root, nil, false)
+}
+
+// connect the Observables form the first one to o, so that an operator after o can re-subscribe it
+func (o *Observable) reconnect(ctx context.Context) {
+	connectStages(ctx, o.root, o, false)
+}
+
+// connect the Observables from root to last (or the end of chain if last is nil).
+// The Observables before an operator that re-subscribes its upstream run with a context
+// that the operator can cancel, so it can drop a pass of its upstream at any time
+// observed reports whether the end of chain is read by Subscribe
+func connectStages(ctx context.Context, root, last *Observable, observed bool) {
+	var stages []*Observable
+	for po := root; po != nil; po = po.next {
+		stages = append(stages, po)
+		if po == last {
+			break
+		}
+	}
+
+	root.connMu.Lock()
+	defer root.connMu.Unlock()
+	if c := root.checkpoint; c != nil {
+		c.load(stages, observed)
+	}
+
+	ctxs := make([]context.Context, len(stages))
+	for i := len(stages) - 1; i >= 0; i-- {
+		ctxs[i] = ctx
+		if stages[i].resubscribe {
+			ctx, stages[i].cancelUpstream = context.WithCancel(ctx)
+		}
+	}
+	// allocate all flows first, a fused stage sends to the flow of a stage connected later
+	fuse(stages)
+	for i, po := range stages {
+		po.outflow = nil // items are passed to the next stage directly, if fused
+		if i+1 == len(stages) || !stages[i+1].fusedIn {
+			po.outflow = make(chan interface{}, po.buf_len)
+		}
+		po.batched = false
+		if po.batchSize > 1 && po.outflow != nil {
+			if i+1 < len(stages) {
+				po.batched = unbatches(stages[i+1])
+			} else {
+				po.batched = observed && po.next == nil
+			}
+		}
+	}
+	for i, po := range stages {
+		po.connectOne(ctxs[i])
+	}
+}
+
+// fuse links consecutive synchronous transform stages, so they run in the goroutine of the first one
+func fuse(stages []*Observable) {
+	fusible := func(po *Observable) bool {
+		_, ok := po.operator.(transOperater)
+		return ok && po.threading == ThreadingDefault && po.root != po
+	}
+	var head *Observable
+	for _, po := range stages {
+		po.fused, po.fusedIn = nil, false
+		if !po.root.fusion || !fusible(po) {
+			head = nil
+		} else if head == nil {
+			head = po
+		} else {
+			head.fused = append(head.fused, po)
+			po.fusedIn = true
+		}
 	}
 }
 
+func (o *Observable) connectOne(ctx context.Context) {
+	if o.fusedIn {
+		return // driven by the first stage of the fused ones
+	}
+	tail := o
+	if n := len(o.fused); n > 0 {
+		tail = o.fused[n-1]
+	}
+	if tail.batched {
+		ctx = tail.newBatcher(ctx)
+	}
+	// the other chains must be running before a combining operator reads them
+	for _, other := range o.others {
+		other.mu.Lock()
+		other.connect(ctx)
+		other.mu.Unlock()
+	}
+	o.operator.op(ctx, o)
+	//fmt.Println("conneted", o.name, o.outflow)
+}
+
+// SetFusion runs consecutive transform operators with ThreadingDefault of the pipeline in one goroutine,
+// passing items by function calls instead of channels
+func (o *Observable) SetFusion(fusion bool) *Observable {
+	o.root.fusion = fusion
+	return o
+}
+
 func (o *Observable) SubscribeOn(t ThreadModel) *Observable {
 	o.threading = t
 	return o
@@ -205,7 +342,7 @@ func (o *Observable) Subscribe(ob interface{}) {
 	}
 
 	//fmt.Println("begin conneted", o.name)
-	o.connect(ctx)
+	connectStages(ctx, o.root, nil, true)
 	if ctxok {
 		oc.OnConnected()
 	}
@@ -215,10 +352,18 @@ func (o *Observable) Subscribe(ob interface{}) {
 	for ; po.next != nil; po = po.next {
 	}
 
-	in := po.outflow
+	in := newFlowReader(po.outflow)
 	o.mu.Unlock()
 
-	for x := range in {
+	for x, ok := in.next(); ok; x, ok = in.next() {
+		if b, ok := x.(*checkpointBarrier); ok {
+			// the items before the barrier are consumed
+			err := b.commit()
+			if err == nil {
+				continue
+			}
+			x = err
+		}
 		if observer != nil {
 			if e, ok := x.(error); ok {
 				observer.OnError(e)
@@ -263,27 +408,68 @@ func (o *Observable) Debug(debug bool) *Observable {
 }
 
 func (o *Observable) sendToFlow(ctx context.Context, item interface{}, out chan interface{}) (end bool) {
+	if o.checkpoint != nil && o.root == o {
+		return o.sendCheckpointed(ctx, item, out)
+	}
+	return o.send(ctx, item, out)
+}
+
+func (o *Observable) send(ctx context.Context, item interface{}, out chan interface{}) (end bool) {
 	//fmt.Println("send chan ", o.name, item, out)
+	if out == nil {
+		// fused with the next stage
+		if ctx.Err() != nil {
+			return true
+		}
+		o.monitor(item)
+		s := fusedStageOf(ctx)
+		return s.flow.process(s.index+1, item)
+	}
+	if o.batchSize > 1 {
+		if b := batcherOf(ctx, out); b != nil {
+			o.monitor(item)
+			return b.add(ctx, item)
+		}
+	}
 	select {
 	case out <- item:
-		if e, ok := item.(error); ok {
-			if o.debug != nil {
-				o.debug.OnError(e)
-			}
-		} else {
-			if o.debug != nil {
-				o.debug.OnNext(item)
-			}
-		}
+		o.monitor(item)
 	case <-ctx.Done():
 		end = true
 	}
 	return
 }
 
-func (o *Observable) closeFlow(out chan interface{}) *Observable {
+func (o *Observable) monitor(item interface{}) {
+	if o.debug == nil {
+		return
+	}
+	if e, ok := item.(error); ok {
+		o.debug.OnError(e)
+	} else if _, ok := item.(*checkpointBarrier); !ok {
+		o.debug.OnNext(item)
+	}
+}
+
+func (o *Observable) closeFlow(ctx context.Context, out chan interface{}) *Observable {
 	// maybe need waiting for parent observable closed
 	//fmt.Println("close chan ", o.name, out)
+	if b := batcherOf(ctx, out); b != nil {
+		b.close(ctx)
+	}
+	// run closers first, so everything is released when the observer completes
+	for _, closer := range o.closers {
+		closer(ctx)
+	}
+	if out == nil {
+		// fused with the next stage, that has no goroutine to close its flow
+		if o.debug != nil {
+			o.debug.OnCompleted()
+		}
+		s := fusedStageOf(ctx)
+		f, i := s.flow, s.index+1
+		return f.stages[i].closeFlow(f.ctxs[i], f.outs[i])
+	}
 	close(out)
 	if o.debug != nil {
 		o.debug.OnCompleted()
@@ -0,0 +1,239 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package rxgotest provides a recording observer and assertions for testing rxgo Observables and operators.
+package rxgotest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yilin0041/service-computing/rxgo"
+)
+
+// Timeout bounds how long an assertion waits for an Observable to complete
+var Timeout = 5 * time.Second
+
+// Kind of notification received by an observer
+type Kind int
+
+const (
+	OnNext Kind = iota
+	OnError
+	OnCompleted
+)
+
+func (k Kind) String() string {
+	switch k {
+	case OnNext:
+		return "OnNext"
+	case OnError:
+		return "OnError"
+	case OnCompleted:
+		return "OnCompleted"
+	}
+	return "Unknown"
+}
+
+// Notification is what a TestObserver received and when, relative to its subscription
+type Notification struct {
+	Kind  Kind
+	Value interface{}
+	Err   error
+	Time  time.Duration
+}
+
+// TestObserver is an rxgo.ObserverWithContext that records all notifications it receives.
+// Subscribed more than once, it records the notifications of all subscriptions and is completed by the first one
+type TestObserver struct {
+	Clock rxgo.Clock // time source of notifications, rxgo.SystemClock if nil
+
+	mu            sync.Mutex
+	ctx           context.Context
+	cancel        context.CancelFunc
+	start         time.Time
+	notifications []Notification
+	done          chan bool
+	complete      sync.Once
+}
+
+var _ rxgo.ObserverWithContext = &TestObserver{}
+
+func NewTestObserver() *TestObserver {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TestObserver{ctx: ctx, cancel: cancel, done: make(chan bool)}
+}
+
+// Subscribe subscribes a new TestObserver to ob in the background, use Await to wait for completion
+func Subscribe(ob *rxgo.Observable) *TestObserver {
+	to := NewTestObserver()
+	go ob.Subscribe(to)
+	return to
+}
+
+func (to *TestObserver) now() time.Time {
+	if to.Clock == nil {
+		return rxgo.SystemClock.Now()
+	}
+	return to.Clock.Now()
+}
+
+func (to *TestObserver) record(n Notification) {
+	to.mu.Lock()
+	defer to.mu.Unlock()
+	n.Time = to.now().Sub(to.start)
+	to.notifications = append(to.notifications, n)
+}
+
+func (to *TestObserver) OnNext(x interface{}) {
+	to.record(Notification{Kind: OnNext, Value: x})
+}
+
+func (to *TestObserver) OnError(e error) {
+	to.record(Notification{Kind: OnError, Err: e})
+}
+
+func (to *TestObserver) OnCompleted() {
+	to.record(Notification{Kind: OnCompleted})
+	to.complete.Do(func() {
+		close(to.done)
+	})
+}
+
+func (to *TestObserver) GetObserverContext() context.Context {
+	to.mu.Lock()
+	defer to.mu.Unlock()
+	to.start = to.now()
+	return to.ctx
+}
+
+func (to *TestObserver) OnConnected() {}
+
+func (to *TestObserver) Unsubscribe() {
+	to.cancel()
+}
+
+// Await waits until the Observable completes or timeout passes, and reports whether it completed
+func (to *TestObserver) Await(timeout time.Duration) bool {
+	select {
+	case <-to.done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// Notifications returns a copy of the notifications received so far
+func (to *TestObserver) Notifications() []Notification {
+	to.mu.Lock()
+	defer to.mu.Unlock()
+	return append([]Notification(nil), to.notifications...)
+}
+
+// Values returns the items received so far
+func (to *TestObserver) Values() []interface{} {
+	res := []interface{}{}
+	for _, n := range to.Notifications() {
+		if n.Kind == OnNext {
+			res = append(res, n.Value)
+		}
+	}
+	return res
+}
+
+// Errors returns the errors received so far
+func (to *TestObserver) Errors() []error {
+	var res []error
+	for _, n := range to.Notifications() {
+		if n.Kind == OnError {
+			res = append(res, n.Err)
+		}
+	}
+	return res
+}
+
+// Completed reports whether OnCompleted is received
+func (to *TestObserver) Completed() bool {
+	select {
+	case <-to.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// subscribe ob and wait for it, failing t if it does not complete in Timeout
+func run(t testing.TB, ob *rxgo.Observable) *TestObserver {
+	t.Helper()
+	to := Subscribe(ob)
+	if !to.Await(Timeout) {
+		to.Unsubscribe()
+		t.Errorf("Observable not completed in %v", Timeout)
+	}
+	return to
+}
+
+// AssertValues checks that ob completes after emitting exactly the expected items in order
+func AssertValues(t testing.TB, ob *rxgo.Observable, expected ...interface{}) *TestObserver {
+	t.Helper()
+	to := run(t, ob)
+	if expected == nil {
+		expected = []interface{}{}
+	}
+	if values := to.Values(); !reflect.DeepEqual(values, expected) {
+		t.Errorf("Values not equal:\n\texpected: %#v\n\tactual  : %#v", expected, values)
+	}
+	return to
+}
+
+// AssertError checks that ob emits an error matching target by errors.Is, or any error if target is nil
+func AssertError(t testing.TB, ob *rxgo.Observable, target error) *TestObserver {
+	t.Helper()
+	to := run(t, ob)
+	errs := to.Errors()
+	for _, e := range errs {
+		if target == nil || errors.Is(e, target) {
+			return to
+		}
+	}
+	if target == nil {
+		t.Errorf("No error emitted")
+	} else {
+		t.Errorf("Error %v not emitted, got %v", target, errs)
+	}
+	return to
+}
+
+// AssertCompleted checks that ob completes in Timeout
+func AssertCompleted(t testing.TB, ob *rxgo.Observable) *TestObserver {
+	t.Helper()
+	return run(t, ob)
+}
+
+// AssertNoLeaks checks that the goroutines started by f have exited in Timeout after it returns
+func AssertNoLeaks(t testing.TB, f func()) {
+	t.Helper()
+	before := runtime.NumGoroutine()
+	f()
+
+	deadline := time.Now().Add(Timeout)
+	for {
+		n := runtime.NumGoroutine()
+		if n <= before {
+			return
+		}
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			buf = buf[:runtime.Stack(buf, true)]
+			t.Errorf("%d goroutines leaked:\n%s", n-before, buf)
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
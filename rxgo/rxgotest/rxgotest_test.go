@@ -0,0 +1,108 @@
+package rxgotest_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/yilin0041/service-computing/rxgo"
+	"github.com/yilin0041/service-computing/rxgo/rxgotest"
+)
+
+// records failures instead of failing the test
+type fakeT struct {
+	testing.TB
+	failed []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.failed = append(f.failed, fmt.Sprintf(format, args...))
+}
+
+func TestAssertValues(t *testing.T) {
+	rxgotest.AssertValues(t, rxgo.Just(1, 2, 3).Map(func(x int) int {
+		return x * 2
+	}), 2, 4, 6)
+	rxgotest.AssertValues(t, rxgo.Empty())
+
+	ft := &fakeT{}
+	rxgotest.AssertValues(ft, rxgo.Just(1, 2), 2, 1)
+	if len(ft.failed) != 1 {
+		t.Errorf("AssertValues should fail, got %v", ft.failed)
+	}
+}
+
+func TestAssertError(t *testing.T) {
+	ee := errors.New("any")
+	rxgotest.AssertError(t, rxgo.Throw(ee), ee)
+	rxgotest.AssertError(t, rxgo.Throw(rxgo.FlowableError{Err: ee}), ee)
+
+	ft := &fakeT{}
+	rxgotest.AssertError(ft, rxgo.Just(1), nil)
+	if len(ft.failed) != 1 {
+		t.Errorf("AssertError should fail, got %v", ft.failed)
+	}
+}
+
+func TestAssertCompleted(t *testing.T) {
+	rxgotest.AssertCompleted(t, rxgo.Range(0, 10))
+
+	timeout := rxgotest.Timeout
+	rxgotest.Timeout = 10 * time.Millisecond
+	defer func() { rxgotest.Timeout = timeout }()
+	ft := &fakeT{}
+	rxgotest.AssertCompleted(ft, rxgo.Never())
+	if len(ft.failed) != 1 {
+		t.Errorf("AssertCompleted should fail, got %v", ft.failed)
+	}
+}
+
+func TestAssertNoLeaks(t *testing.T) {
+	rxgotest.AssertNoLeaks(t, func() {
+		rxgotest.AssertValues(t, rxgo.Just(1, 2).Map(func(x int) int {
+			return x
+		}), 1, 2)
+	})
+
+	timeout := rxgotest.Timeout
+	rxgotest.Timeout = 10 * time.Millisecond
+	defer func() { rxgotest.Timeout = timeout }()
+	stop := make(chan bool)
+	defer close(stop)
+	ft := &fakeT{}
+	rxgotest.AssertNoLeaks(ft, func() {
+		go func() { <-stop }()
+	})
+	if len(ft.failed) != 1 {
+		t.Errorf("AssertNoLeaks should fail, got %v", ft.failed)
+	}
+}
+
+func TestTestObserver(t *testing.T) {
+	clock := rxgo.NewVirtualClock(time.Unix(0, 0))
+	to := rxgotest.NewTestObserver()
+	to.Clock = clock
+	rxgo.Just(1).Subscribe(to)
+
+	ns := to.Notifications()
+	if len(ns) != 2 || ns[0].Kind != rxgotest.OnNext || ns[0].Value != 1 || ns[1].Kind != rxgotest.OnCompleted {
+		t.Errorf("Notifications not recorded, got %v", ns)
+	}
+	if !to.Completed() || ns[0].Time != 0 {
+		t.Errorf("TestObserver state error")
+	}
+}
+
+func TestTestObserverResubscribe(t *testing.T) {
+	to := rxgotest.NewTestObserver()
+	ob := rxgo.Just(1)
+	ob.Subscribe(to)
+	ob.Subscribe(to)
+
+	if vs := to.Values(); len(vs) != 2 || !to.Completed() {
+		t.Errorf("Resubscribed TestObserver error, got %v", to.Notifications())
+	}
+}
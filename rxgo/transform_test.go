@@ -3,6 +3,7 @@ package rxgo_test
 import (
 	"context"
 	"errors"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -97,3 +98,136 @@ func TestFilter(t *testing.T) {
 
 	assert.Equal(t, []int{0, 7, 2}, res, "Map Test Error!")
 }
+
+func fusedPipeline(fusion bool, finally func()) *rxgo.Observable {
+	return rxgo.Range(0, 10).SetFusion(fusion).Map(func(x int) int {
+		return x + 1
+	}).Filter(func(x int) bool {
+		return x%2 == 1
+	}).Map(func(x int) interface{} {
+		if x == 5 {
+			return errors.New("five")
+		}
+		return x
+	}).Finally(finally).Map(func(x int) int {
+		if x > 7 {
+			panic(rxgo.ErrEoFlow)
+		}
+		return x * 10
+	})
+}
+
+func TestOperatorFusion(t *testing.T) {
+	collect := func(fusion bool) ([]int, int, int) {
+		res, errs, finally := []int{}, 0, 0
+		fusedPipeline(fusion, func() {
+			finally++
+		}).Subscribe(rxgo.ObserverMonitor{
+			Next: func(x interface{}) {
+				res = append(res, x.(int))
+			},
+			Error: func(e error) {
+				errs++
+			},
+		})
+		return res, errs, finally
+	}
+
+	res, errs, finally := collect(true)
+	assert.Equal(t, []int{10, 30, 70}, res, "Operator Fusion Test Error!")
+	assert.Equal(t, []int{1, 1}, []int{errs, finally}, "Operator Fusion Test Error!")
+
+	res1, errs1, finally1 := collect(false)
+	assert.Equal(t, res, res1, "Operator Fusion Test Error!")
+	assert.Equal(t, []int{errs, finally}, []int{errs1, finally1}, "Operator Fusion Test Error!")
+}
+
+func TestOperatorFusionSnapshot(t *testing.T) {
+	ob := rxgo.Just(1, 2).SetFusion(true).Map(func(x int) int {
+		return x
+	}).Map(func(x int) int {
+		return x
+	}).Map(func(x int) int {
+		return x
+	}).SubscribeOn(rxgo.ThreadingIO).Map(func(x int) int {
+		return x
+	}).Map(func(x int) int {
+		return x
+	})
+	ob.Subscribe(func(x int) {})
+
+	fused := []bool{}
+	for _, s := range ob.Snapshot() {
+		fused = append(fused, s.Fused)
+	}
+	assert.Equal(t, []bool{false, true, false, false, true, false}, fused, "Operator Fusion Test Error!")
+}
+
+func TestOperatorFusionRetry(t *testing.T) {
+	var failures int32
+	res := []int{}
+	rxgo.Range(0, 50).SetFusion(true).Map(func(x int) int {
+		return x
+	}).Map(func(x int) interface{} {
+		if x == 10 && atomic.AddInt32(&failures, 1) <= 4 {
+			return errors.New("flaky")
+		}
+		return x
+	}).RetryWithBackoff(rxgo.BackoffPolicy{}).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+
+	expected := []int{}
+	for i := 0; i < 4; i++ {
+		for x := 0; x < 10; x++ {
+			expected = append(expected, x)
+		}
+	}
+	for x := 0; x < 50; x++ {
+		expected = append(expected, x)
+	}
+	assert.Equal(t, expected, res, "Operator Fusion Retry Test Error!")
+}
+
+func TestOperatorFusionRepeat(t *testing.T) {
+	res := []int{}
+	rxgo.Just(1, 2).SetFusion(true).Map(func(x int) int {
+		return 10 * x
+	}).Map(func(x int) int {
+		return x + 1
+	}).Repeat(3).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+
+	assert.Equal(t, []int{11, 21, 11, 21, 11, 21}, res, "Operator Fusion Repeat Test Error!")
+}
+
+// Fusion only saves the channel hops between stages, user functions are called by reflection either way
+// and that takes most of the time: on one CPU Pipeline10Fused is about 10% faster than Pipeline10
+func benchmarkPipeline(b *testing.B, stages int, fusion bool) {
+	for i := 0; i < b.N; i++ {
+		ob := rxgo.Range(0, 10000).SetFusion(fusion)
+		for j := 0; j < stages; j++ {
+			ob = ob.Map(func(x int) int {
+				return x + 1
+			})
+		}
+		ob.Subscribe(func(x int) {})
+	}
+}
+
+func BenchmarkPipeline10(b *testing.B) {
+	benchmarkPipeline(b, 10, false)
+}
+
+func BenchmarkPipeline10Fused(b *testing.B) {
+	benchmarkPipeline(b, 10, true)
+}
+
+func BenchmarkPipeline2(b *testing.B) {
+	benchmarkPipeline(b, 2, false)
+}
+
+func BenchmarkPipeline2Fused(b *testing.B) {
+	benchmarkPipeline(b, 2, true)
+}
@@ -26,17 +26,25 @@ type transOperater struct {
 func (tsop transOperater) op(ctx context.Context, o *Observable) {
 	// must hold defintion of flow resourcs here, such as chan etc., that is allocated when connected
 	// this resurces may be changed when operation routine is running.
-	in := o.pred.outflow
+	in := newFlowReader(o.pred.outflow)
 	out := o.outflow
 	//fmt.Println(o.name, "operator in/out chan ", in, out)
 	var wg sync.WaitGroup
+	if len(o.fused) > 0 {
+		ctx = o.newFusedFlow(ctx)
+	}
 
 	go func() {
 		end := false
-		for x := range in {
+		for x, ok := in.next(); ok; x, ok = in.next() {
 			if end {
 				continue
 			}
+			if b, ok := x.(*checkpointBarrier); ok {
+				wg.Wait() // items before the barrier must be sent first
+				end = o.passBarrier(ctx, b, out)
+				continue
+			}
 			// can not pass a interface as parameter (pointer) to gorountion for it may change its value outside!
 			xv := reflect.ValueOf(x)
 			// send an error to stream if the flip not accept error
@@ -65,10 +73,61 @@ func (tsop transOperater) op(ctx context.Context, o *Observable) {
 		}
 
 		wg.Wait() //waiting all go-routines completed
-		o.closeFlow(out)
+		o.closeFlow(ctx, out)
 	}()
 }
 
+// the stages fused by a connection, they are run by the goroutine of the first one
+type fusedFlow struct {
+	stages []*Observable
+	ops    []transOperater
+	outs   []chan interface{} // only the last stage has a flow
+	ctxs   []context.Context  // the context of each stage, it tells the stage where it is fused
+	ends   []bool
+}
+
+// a stage of a fusedFlow, attached to the context its operator runs with
+type fusedStage struct {
+	flow  *fusedFlow
+	index int
+}
+
+type fusedStageKey struct{}
+
+// link o and the stages fused with it to a new fusedFlow, and return the context of o.
+// The flow belongs to this connection, a cancelled one never sees the flow of a later one
+func (o *Observable) newFusedFlow(ctx context.Context) context.Context {
+	f := &fusedFlow{stages: append([]*Observable{o}, o.fused...)}
+	for i, po := range f.stages {
+		f.ops = append(f.ops, po.operator.(transOperater))
+		f.outs = append(f.outs, po.outflow)
+		f.ctxs = append(f.ctxs, context.WithValue(ctx, fusedStageKey{}, fusedStage{f, i}))
+	}
+	f.ends = make([]bool, len(f.stages))
+	return f.ctxs[0]
+}
+
+// the fused stage that runs with ctx
+func fusedStageOf(ctx context.Context) fusedStage {
+	return ctx.Value(fusedStageKey{}).(fusedStage)
+}
+
+// process an item sent to the i-th stage, and report whether the flow is cancelled
+func (f *fusedFlow) process(i int, x interface{}) (end bool) {
+	if f.ends[i] {
+		return false // drop items like a stopped stage draining its input
+	}
+	ctx, o, out := f.ctxs[i], f.stages[i], f.outs[i]
+	if b, ok := x.(*checkpointBarrier); ok {
+		f.ends[i] = o.passBarrier(ctx, b, out)
+	} else if e, ok := x.(error); ok && !o.flip_accept_error {
+		o.sendToFlow(ctx, e, out)
+	} else if f.ops[i].opFunc(ctx, o, reflect.ValueOf(x), out) {
+		f.ends[i] = true
+	}
+	return ctx.Err() != nil
+}
+
 func (parent *Observable) TransformOp(tf transformFunc) (o *Observable) {
 	o = parent.newTransformObservable("customTransform")
 	o.flip_accept_error = true
@@ -116,7 +175,6 @@ var mapOperater = transOperater{func(ctx context.Context, o *Observable, x refle
 	var params = []reflect.Value{x}
 	rs, skip, stop, e := userFuncCall(fv, params)
 
-	var item interface{} = rs[0].Interface()
 	if stop {
 		end = true
 		return
@@ -124,8 +182,11 @@ var mapOperater = transOperater{func(ctx context.Context, o *Observable, x refle
 	if skip {
 		return
 	}
+	var item interface{}
 	if e != nil {
 		item = e
+	} else {
+		item = rs[0].Interface()
 	}
 	// send data
 	if !end {
@@ -163,8 +224,6 @@ var flatMapOperater = transOperater{func(ctx context.Context, o *Observable, x r
 	//fmt.Println("x is ", x)
 	rs, skip, stop, e := userFuncCall(fv, params)
 
-	var item = rs[0].Interface().(*Observable)
-
 	if stop {
 		end = true
 		return
@@ -179,6 +238,7 @@ var flatMapOperater = transOperater{func(ctx context.Context, o *Observable, x r
 		}
 		return
 	}
+	var item = rs[0].Interface().(*Observable)
 	// send data
 	if !end {
 		if item != nil {
@@ -227,7 +287,6 @@ var filterOperater = transOperater{func(ctx context.Context, o *Observable, x re
 	var params = []reflect.Value{x}
 	rs, skip, stop, e := userFuncCall(fv, params)
 
-	var item interface{} = rs[0].Interface()
 	if stop {
 		end = true
 		return
@@ -235,8 +294,11 @@ var filterOperater = transOperater{func(ctx context.Context, o *Observable, x re
 	if skip {
 		return
 	}
+	var item interface{}
 	if e != nil {
 		item = e
+	} else {
+		item = rs[0].Interface()
 	}
 	// send data
 	if !end {